@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/history"
+	"github.com/augustoccesar/go-ranking/internal/lineage"
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/internal/spider/thescore"
+	"github.com/augustoccesar/go-ranking/internal/store"
+	"github.com/augustoccesar/go-ranking/internal/updater"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// parseDate parses the value of a date flag. A plain date is the start of the
+// day in UTC, or the end of it when endOfDay is set.
+func parseDate(flag, value string, endOfDay bool) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.UTC(), nil
+	}
+	if parsed, err := time.Parse("2006-01-02", value); err == nil {
+		if endOfDay {
+			parsed = parsed.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		return parsed, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --%s %q: expected RFC3339 (2019-03-01T00:00:00Z) or YYYY-MM-DD", flag, value)
+}
+
+// validate checks the global flags and parses the dates.
+func (p *InputParams) validate() error {
+	if !checkSource(p.Source) {
+		return fmt.Errorf("invalid --source %q: available sources are %s", p.Source, strings.Join(AvailableSources, ", "))
+	}
+
+	var err error
+	if p.startDate, err = parseDate("start_date", p.StartDate, false); err != nil {
+		return err
+	}
+	if p.endDate, err = parseDate("end_date", p.EndDate, true); err != nil {
+		return err
+	}
+	if !p.endDate.After(p.startDate) {
+		return fmt.Errorf("--end_date (%s) must be after --start_date (%s)",
+			p.endDate.Format(time.RFC3339), p.startDate.Format(time.RFC3339))
+	}
+
+	switch {
+	case p.PeriodDuration <= 0:
+		return fmt.Errorf("invalid --period_duration %d: must be at least 1 day", p.PeriodDuration)
+	case p.Tau <= 0:
+		return fmt.Errorf("invalid --tau %g: must be positive", p.Tau)
+	case p.DefaultRD <= 0:
+		return fmt.Errorf("invalid --default_rd %g: must be positive", p.DefaultRD)
+	case p.DefaultSigma <= 0:
+		return fmt.Errorf("invalid --default_volatility %g: must be positive", p.DefaultSigma)
+	}
+
+	switch {
+	case p.Top < 0:
+		return fmt.Errorf("invalid --top %d: must not be negative", p.Top)
+	case p.MinMatches < 0:
+		return fmt.Errorf("invalid --min_matches %d: must not be negative", p.MinMatches)
+	case p.MaxRD < 0:
+		return fmt.Errorf("invalid --max_rd %g: must not be negative", p.MaxRD)
+	case p.ConservativeK < 0:
+		return fmt.Errorf("invalid --conservative_k %g: must not be negative", p.ConservativeK)
+	}
+	if p.ActiveWithin != "" {
+		if p.activeWithin, err = output.ParseDuration(p.ActiveWithin); err != nil || p.activeWithin <= 0 {
+			return fmt.Errorf("invalid --active_within %q: expected a positive duration like 30d or 720h", p.ActiveWithin)
+		}
+	}
+	if _, err = output.ParseSortKey(p.SortBy); err != nil {
+		return err
+	}
+
+	_, err = p.format()
+	return err
+}
+
+// standings builds empty Standings with the conservative rating of the flags.
+func (p *InputParams) standings() *output.Standings {
+	standings := output.BuildStandings()
+	standings.ConservativeK = p.ConservativeK
+	return standings
+}
+
+// leaderboard builds the Leaderboard of the flags.
+func (p *InputParams) leaderboard() *output.Leaderboard {
+	sortBy, _ := output.ParseSortKey(p.SortBy)
+	return &output.Leaderboard{
+		Top:          p.Top,
+		MinMatches:   p.MinMatches,
+		MaxRD:        p.MaxRD,
+		ActiveWithin: p.activeWithin,
+		SortBy:       sortBy,
+	}
+}
+
+// ranking builds the Rows of the point-in-time ranking at the moment, with the
+// totals of the periods closed until then. The Leaderboard isn't applied.
+func (p *InputParams) ranking(periods []*glicko.RatingPeriod, teams map[int]*ranking.Team, at time.Time) []*output.Row {
+	return p.standings().RankingAt(history.BuildHistory(periods), teams, at)
+}
+
+// dates validates the global flags and returns the parsed start and end
+// dates. Every command calls it before doing anything else.
+func (p *InputParams) dates() (startDate, endDate time.Time, err error) {
+	if err := p.validate(); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return p.startDate, p.endDate, nil
+}
+
+// resolver loads the lineage Resolver from the aliases file. Without a file a
+// nil Resolver is returned, which resolves every id to itself.
+func (p *InputParams) resolver() (*lineage.Resolver, error) {
+	if p.AliasesFile == "" {
+		return nil, nil
+	}
+
+	aliases, err := lineage.LoadFile(p.AliasesFile)
+	if err != nil {
+		return nil, err
+	}
+	return lineage.BuildResolver(aliases), nil
+}
+
+// defaultRating builds the Rating of the Teams on their first period based on
+// the flags.
+func (p *InputParams) defaultRating() *glicko.Rating {
+	return glicko.BuildRating(p.DefaultRating, p.DefaultRD, p.DefaultSigma)
+}
+
+// fetcher returns the updater.Fetcher of the selected source.
+func (p *InputParams) fetcher() (updater.Fetcher, error) {
+	switch p.Source {
+	case "thescore":
+		return fetchTheScore, nil
+	case "manual":
+		// Nothing to fetch, the Matches are the stored ones.
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown source %q", p.Source)
+}
+
+// openStore opens the database of the --database flag.
+func (p *InputParams) openStore(command string) (*store.SQLiteStore, error) {
+	if p.Database == "" {
+		return nil, fmt.Errorf("%s needs --database", command)
+	}
+	return store.OpenSQLite(p.Database)
+}
+
+// buildRanker builds a Ranker configured by the flags.
+func (p *InputParams) buildRanker(startDate, endDate time.Time, resolver *lineage.Resolver) *ranking.Ranker {
+	ranker := ranking.BuildRanker(startDate, endDate, p.PeriodDuration, resolver)
+	ranker.SystemConstant = p.Tau
+	ranker.DefaultRating = p.defaultRating()
+	ranker.Parallel = p.Parallel
+	return ranker
+}
+
+// loadPeriods gets the calculated periods that start between the dates: from
+// the database when --database is set, calculating them from the source
+// otherwise. The stored periods keep the lineages they were calculated with,
+// the resolver is only used for the ones calculated from the source.
+func (p *InputParams) loadPeriods(startDate, endDate time.Time, resolver *lineage.Resolver) ([]*glicko.RatingPeriod, map[int]*ranking.Team, error) {
+	if p.Database != "" {
+		db, err := store.OpenSQLite(p.Database)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer db.Close()
+
+		storedPeriods, err := db.Periods()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		periods := []*glicko.RatingPeriod{}
+		for _, ratingPeriod := range storedPeriods {
+			if !ratingPeriod.StartDate.Before(startDate) && ratingPeriod.StartDate.Before(endDate) {
+				periods = append(periods, ratingPeriod)
+			}
+		}
+		teams, err := db.Teams()
+		return periods, teams, err
+	}
+
+	matches, err := p.fetchMatches(startDate, endDate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ranker := p.buildRanker(startDate, endDate, resolver)
+	periods, err := ranker.Rate(matches)
+	return periods, ranker.Teams, err
+}
+
+// latestRatings returns the last PostRating of each Competitor on the periods.
+func latestRatings(periods []*glicko.RatingPeriod) map[int]*glicko.Rating {
+	ratings := map[int]*glicko.Rating{}
+	for _, ratingPeriod := range periods {
+		for _, competitor := range ratingPeriod.Competitors {
+			if competitor.PostRating != nil {
+				ratings[competitor.ID] = competitor.PostRating
+			}
+		}
+	}
+	return ratings
+}
+
+// teamName returns the name of the Team, or its id when unknown.
+func teamName(teams map[int]*ranking.Team, id int) string {
+	if team, ok := teams[id]; ok {
+		return team.Name
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+// writeSnapshot writes the Snapshot on the file, as gob when the file ends
+// with .gob and as JSON otherwise.
+func writeSnapshot(path string, snapshot *glicko.Snapshot) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if strings.HasSuffix(path, ".gob") {
+		return snapshot.WriteGob(file)
+	}
+	return snapshot.WriteJSON(file)
+}
+
+// fetchTheScore is the updater.Fetcher for TheScore.
+func fetchTheScore(startDate, endDate time.Time) ([]*ranking.Match, error) {
+	periodData, err := thescore.FetchPeriodData(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return ranking.FromTheScore(periodData.Matches), nil
+}
+
+// fetchMatches fetches the Matches between the dates from the selected source.
+func (p *InputParams) fetchMatches(startDate, endDate time.Time) ([]*ranking.Match, error) {
+	fetch, err := p.fetcher()
+	if err != nil {
+		return nil, err
+	}
+	if fetch == nil {
+		return nil, fmt.Errorf("the %s source only has the Matches of the --database", p.Source)
+	}
+	return fetch(startDate, endDate)
+}
+
+// format validates the --format flag.
+func (p *InputParams) format() (output.Format, error) {
+	return output.ParseFormat(p.Format)
+}
+
+// writeRows ranks the Rows by the leaderboard flags and writes them on the
+// --output file, or on stdout when it is not set.
+func (p *InputParams) writeRows(format output.Format, rows []*output.Row) error {
+	rows = p.leaderboard().Apply(rows)
+
+	return p.writeOutput(func(w io.Writer) error {
+		return output.Write(w, format, rows)
+	})
+}
+
+// writeOutput calls write with the --output file, or with stdout when it is
+// not set.
+func (p *InputParams) writeOutput(write func(w io.Writer) error) error {
+	if p.Output == "" {
+		return write(os.Stdout)
+	}
+
+	file, err := os.Create(p.Output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		return err
+	}
+	return file.Close()
+}
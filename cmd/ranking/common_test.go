@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDates(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		startDate time.Time
+		endDate   time.Time
+	}{
+		{
+			name:      "rfc3339",
+			args:      []string{"--start_date", "2019-03-01T10:00:00Z", "--end_date", "2019-03-31T12:00:00+02:00"},
+			startDate: time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2019, 3, 31, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "plain dates",
+			args:      []string{"--start_date", "2019-03-01", "--end_date", "2019-03-31"},
+			startDate: time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2019, 3, 31, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:      "same plain date",
+			args:      []string{"--start_date", "2019-03-01", "--end_date", "2019-03-01"},
+			startDate: time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2019, 3, 1, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inputParams, err := runApp(test.args...)
+			assert.Nil(t, err)
+
+			startDate, endDate, err := inputParams.dates()
+			assert.Nil(t, err)
+			assert.Equal(t, test.startDate, startDate)
+			assert.Equal(t, test.endDate, endDate)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		error string
+	}{
+		{name: "invalid rfc3339 start", args: []string{"--start_date", "2019-02-30T00:00:00Z"}, error: "--start_date"},
+		{name: "invalid plain start", args: []string{"--start_date", "2019-02-30"}, error: "--start_date"},
+		{name: "invalid rfc3339 end", args: []string{"--end_date", "2019-03-01T25:00:00Z"}, error: "--end_date"},
+		{name: "invalid plain end", args: []string{"--end_date", "2019-3-1"}, error: "--end_date"},
+		{
+			name:  "end before start",
+			args:  []string{"--start_date", "2019-03-31", "--end_date", "2019-03-01"},
+			error: "must be after --start_date",
+		},
+		{
+			name:  "end equal to start",
+			args:  []string{"--start_date", "2019-03-01T00:00:00Z", "--end_date", "2019-03-01T00:00:00Z"},
+			error: "must be after --start_date",
+		},
+		{name: "zero period duration", args: []string{"--period_duration", "0"}, error: "--period_duration"},
+		{name: "negative period duration", args: []string{"--period_duration", "-7"}, error: "--period_duration"},
+		{name: "unknown source", args: []string{"--source", "hltv"}, error: "--source"},
+		{name: "zero tau", args: []string{"--tau", "0"}, error: "--tau"},
+		{name: "negative top", args: []string{"--top", "-1"}, error: "--top"},
+		{name: "invalid active within", args: []string{"--active_within", "soon"}, error: "--active_within"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inputParams, err := runApp(test.args...)
+			assert.Nil(t, err)
+
+			_, _, err = inputParams.dates()
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), test.error)
+			}
+		})
+	}
+
+	// The defaults are valid.
+	inputParams, err := runApp()
+	assert.Nil(t, err)
+	assert.Nil(t, inputParams.validate())
+}
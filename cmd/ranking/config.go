@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the effective configuration of the global flags, grouped the same
+// way as on the config files.
+type Config struct {
+	Data struct {
+		Source    string `yaml:"source" json:"source"`
+		Database  string `yaml:"database" json:"database"`
+		Aliases   string `yaml:"aliases" json:"aliases"`
+		StartDate string `yaml:"start_date" json:"start_date"`
+		EndDate   string `yaml:"end_date" json:"end_date"`
+	} `yaml:"data" json:"data"`
+	Period struct {
+		PeriodDuration int `yaml:"period_duration" json:"period_duration"`
+	} `yaml:"period" json:"period"`
+	Glicko struct {
+		Tau               float64 `yaml:"tau" json:"tau"`
+		DefaultRating     float64 `yaml:"default_rating" json:"default_rating"`
+		DefaultRD         float64 `yaml:"default_rd" json:"default_rd"`
+		DefaultVolatility float64 `yaml:"default_volatility" json:"default_volatility"`
+		Parallel          bool    `yaml:"parallel" json:"parallel"`
+	} `yaml:"glicko" json:"glicko"`
+	Leaderboard struct {
+		Top           int     `yaml:"top" json:"top"`
+		MinMatches    int     `yaml:"min_matches" json:"min_matches"`
+		MaxRD         float64 `yaml:"max_rd" json:"max_rd"`
+		ActiveWithin  string  `yaml:"active_within" json:"active_within"`
+		Sort          string  `yaml:"sort" json:"sort"`
+		ConservativeK float64 `yaml:"conservative_k" json:"conservative_k"`
+	} `yaml:"leaderboard" json:"leaderboard"`
+	Output struct {
+		Format        string `yaml:"format" json:"format"`
+		Output        string `yaml:"output" json:"output"`
+		Snapshot      string `yaml:"snapshot" json:"snapshot"`
+		SuggestMerges bool   `yaml:"suggest_merges" json:"suggest_merges"`
+	} `yaml:"output" json:"output"`
+}
+
+// config builds the effective Config of the flags.
+func (p *InputParams) config() *Config {
+	config := &Config{}
+	config.Data.Source = p.Source
+	config.Data.Database = p.Database
+	config.Data.Aliases = p.AliasesFile
+	config.Data.StartDate = p.StartDate
+	config.Data.EndDate = p.EndDate
+	config.Period.PeriodDuration = p.PeriodDuration
+	config.Glicko.Tau = p.Tau
+	config.Glicko.DefaultRating = p.DefaultRating
+	config.Glicko.DefaultRD = p.DefaultRD
+	config.Glicko.DefaultVolatility = p.DefaultSigma
+	config.Glicko.Parallel = p.Parallel
+	config.Leaderboard.Top = p.Top
+	config.Leaderboard.MinMatches = p.MinMatches
+	config.Leaderboard.MaxRD = p.MaxRD
+	config.Leaderboard.ActiveWithin = p.ActiveWithin
+	config.Leaderboard.Sort = p.SortBy
+	config.Leaderboard.ConservativeK = p.ConservativeK
+	config.Output.Format = p.Format
+	config.Output.Output = p.Output
+	config.Output.Snapshot = p.SnapshotFile
+	config.Output.SuggestMerges = p.SuggestMerges
+	return config
+}
+
+// loadConfig reads the config file (YAML, TOML or JSON, by its extension) and
+// sets the global flags that weren't given on the command line. The keys are
+// the names of the flags, optionally grouped in sections (as printed by
+// `config print`) whose names are ignored.
+func loadConfig(c *cli.Context, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("config %s: unknown format, use .yaml, .toml or .json", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config %s: %v", path, err)
+	}
+
+	flat := map[string]string{}
+	if err := flattenConfig(values, flat); err != nil {
+		return fmt.Errorf("config %s: %v", path, err)
+	}
+
+	known := map[string]bool{}
+	for _, flag := range c.App.Flags {
+		known[flag.GetName()] = true
+	}
+
+	for name, value := range flat {
+		if !known[name] || name == "config" {
+			return fmt.Errorf("config %s: unknown key %q", path, name)
+		}
+		if c.IsSet(name) {
+			continue
+		}
+		if err := c.Set(name, value); err != nil {
+			return fmt.Errorf("config %s: invalid %s %q: %v", path, name, value, err)
+		}
+	}
+
+	return nil
+}
+
+// flattenConfig collects the values of the (possibly nested) config on flat,
+// keyed by the name of the flag.
+func flattenConfig(values map[string]interface{}, flat map[string]string) error {
+	for key, value := range values {
+		var err error
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			err = flattenConfig(typed, flat)
+		case map[interface{}]interface{}:
+			section := map[string]interface{}{}
+			for sectionKey, sectionValue := range typed {
+				section[fmt.Sprint(sectionKey)] = sectionValue
+			}
+			err = flattenConfig(section, flat)
+		case time.Time:
+			layout := time.RFC3339
+			if typed.Location().String() == "date-local" {
+				// Plain TOML date, kept as YYYY-MM-DD so it means the same as
+				// on the command line.
+				layout = "2006-01-02"
+			}
+			err = setConfigValue(flat, key, typed.Format(layout))
+		case float64:
+			// JSON numbers are all float64, and fmt.Sprint would write the
+			// big ones in exponent form (1e+06), which int flags reject.
+			err = setConfigValue(flat, key, strconv.FormatFloat(typed, 'f', -1, 64))
+		case []interface{}, nil:
+			err = fmt.Errorf("invalid value of %q", key)
+		default:
+			err = setConfigValue(flat, key, fmt.Sprint(typed))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setConfigValue(flat map[string]string, key, value string) error {
+	if _, ok := flat[key]; ok {
+		return fmt.Errorf("duplicated key %q", key)
+	}
+	flat[key] = value
+	return nil
+}
+
+// configCommand builds the command with the subcommands about the config.
+func configCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "config",
+		Usage: "Inspect the configuration.",
+		Subcommands: []cli.Command{
+			{
+				Name:  "print",
+				Usage: "Print the effective configuration, merging the --config file with the flags (JSON with --format json, YAML otherwise).",
+				Action: func(c *cli.Context) error {
+					if _, _, err := inputParams.dates(); err != nil {
+						return err
+					}
+					format, err := inputParams.format()
+					if err != nil {
+						return err
+					}
+
+					// The --output is where the config goes, and kept on it
+					// every command run with the config would overwrite it.
+					config := inputParams.config()
+					config.Output.Output = ""
+
+					return inputParams.writeOutput(func(w io.Writer) error {
+						if format == output.FormatJSON {
+							encoder := json.NewEncoder(w)
+							encoder.SetIndent("", "  ")
+							return encoder.Encode(config)
+						}
+
+						data, err := yaml.Marshal(config)
+						if err != nil {
+							return err
+						}
+						_, err = w.Write(data)
+						return err
+					})
+				},
+			},
+		},
+	}
+}
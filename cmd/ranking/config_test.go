@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+// runApp runs the CLI with the arguments, doing nothing when no command is
+// given, and returns the InputParams it ended with.
+func runApp(args ...string) (*InputParams, error) {
+	inputParams := &InputParams{}
+	app := buildApp(inputParams)
+	app.Writer = ioutil.Discard
+	app.ErrWriter = ioutil.Discard
+	app.Action = func(c *cli.Context) error { return nil }
+
+	err := app.Run(append([]string{"ranking"}, args...))
+	return inputParams, err
+}
+
+func writeConfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		args    []string
+		check   func(t *testing.T, p *InputParams)
+	}{
+		{
+			name:    "flags override the file",
+			file:    "ranking.yaml",
+			content: "top: 10\nmin_matches: 3\n",
+			args:    []string{"--top", "5"},
+			check: func(t *testing.T, p *InputParams) {
+				assert.Equal(t, 5, p.Top)
+				assert.Equal(t, 3, p.MinMatches)
+			},
+		},
+		{
+			name:    "nested sections",
+			file:    "ranking.yaml",
+			content: "data:\n  source: manual\nglicko:\n  tau: 0.7\n  parallel: true\nleaderboard:\n  sort: conservative\n",
+			check: func(t *testing.T, p *InputParams) {
+				assert.Equal(t, "manual", p.Source)
+				assert.Equal(t, 0.7, p.Tau)
+				assert.True(t, p.Parallel)
+				assert.Equal(t, "conservative", p.SortBy)
+			},
+		},
+		{
+			name:    "toml native dates",
+			file:    "ranking.toml",
+			content: "[data]\nstart_date = 2019-03-01\nend_date = 2019-03-31T12:00:00Z\n",
+			check: func(t *testing.T, p *InputParams) {
+				assert.Equal(t, "2019-03-01", p.StartDate)
+				assert.Equal(t, "2019-03-31T12:00:00Z", p.EndDate)
+
+				startDate, endDate, err := p.dates()
+				assert.Nil(t, err)
+				assert.Equal(t, time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), startDate)
+				assert.Equal(t, time.Date(2019, 3, 31, 12, 0, 0, 0, time.UTC), endDate)
+			},
+		},
+		{
+			name:    "large json integers",
+			file:    "ranking.json",
+			content: `{"period": {"period_duration": 1000000}, "leaderboard": {"top": 25000000, "max_rd": 0.5}}`,
+			check: func(t *testing.T, p *InputParams) {
+				assert.Equal(t, 1000000, p.PeriodDuration)
+				assert.Equal(t, 25000000, p.Top)
+				assert.Equal(t, 0.5, p.MaxRD)
+			},
+		},
+		{
+			name:    "large toml integers",
+			file:    "ranking.toml",
+			content: "[period]\nperiod_duration = 1000000\n",
+			check: func(t *testing.T, p *InputParams) {
+				assert.Equal(t, 1000000, p.PeriodDuration)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeConfig(t, test.file, test.content)
+
+			inputParams, err := runApp(append([]string{"--config", path}, test.args...)...)
+			assert.Nil(t, err)
+			test.check(t, inputParams)
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "unknown key", file: "ranking.yaml", content: "tops: 10\n"},
+		{name: "duplicated key", file: "ranking.yaml", content: "top: 10\nleaderboard:\n  top: 5\n"},
+		{name: "invalid value", file: "ranking.yaml", content: "top: many\n"},
+		{name: "list value", file: "ranking.json", content: `{"top": [1, 2]}`},
+		{name: "unknown format", file: "ranking.ini", content: "top = 10\n"},
+		{name: "invalid syntax", file: "ranking.json", content: `{"top": `},
+		{name: "config inside the config", file: "ranking.yaml", content: "config: other.yaml\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := runApp("--config", writeConfig(t, test.file, test.content))
+			assert.NotNil(t, err)
+		})
+	}
+
+	_, err := runApp("--config", filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.NotNil(t, err)
+}
+
+func TestConfigPrint(t *testing.T) {
+	for _, file := range []string{"ranking.yaml", "ranking.json"} {
+		t.Run(file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), file)
+			format := "table"
+			if filepath.Ext(file) == ".json" {
+				format = "json"
+			}
+
+			_, err := runApp("--tau", "0.4", "--top", "5", "--format", format, "--output", path, "config", "print")
+			assert.Nil(t, err)
+
+			inputParams, err := runApp("--config", path)
+			assert.Nil(t, err)
+			assert.Equal(t, 0.4, inputParams.Tau)
+			assert.Equal(t, 5, inputParams.Top)
+			assert.Equal(t, format, inputParams.Format)
+			assert.Equal(t, "", inputParams.Output)
+		})
+	}
+}
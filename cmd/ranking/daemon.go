@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/api"
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/internal/updater"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/urfave/cli"
+)
+
+// daemonCommand builds the command that keeps the database up to date,
+// running update on a schedule.
+func daemonCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "daemon",
+		Usage: "Fetch, rate and persist the periods closed since the last run every --interval (requires --database). With --addr it serves its status on /health and the JSON API of serve on /api/. Stops on SIGTERM or SIGINT once the run in progress is over.",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "interval",
+				Value:       "1h",
+				Usage:       "Time between runs, like 30m or 1d.",
+				Destination: &inputParams.Interval,
+			},
+			cli.StringFlag{
+				Name:        "addr",
+				Usage:       "Address to serve /health and the API on (disabled by default).",
+				Destination: &inputParams.Addr,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			parsedStartDate, _, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+
+			interval, err := output.ParseDuration(inputParams.Interval)
+			if err != nil || interval <= 0 {
+				return fmt.Errorf("invalid --interval %q: expected a positive duration like 30m or 1d", inputParams.Interval)
+			}
+
+			fetch, err := inputParams.fetcher()
+			if err != nil {
+				return err
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			db, err := inputParams.openStore("daemon")
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			periodUpdater := updater.BuildUpdater(db, fetch, parsedStartDate, inputParams.PeriodDuration, resolver)
+			periodUpdater.SystemConstant = inputParams.Tau
+			periodUpdater.DefaultRating = inputParams.defaultRating()
+			periodUpdater.Parallel = inputParams.Parallel
+			standings := inputParams.standings()
+			storedPeriods, err := db.Periods()
+			if err != nil {
+				return err
+			}
+			for _, ratingPeriod := range storedPeriods {
+				standings.Add(ratingPeriod, nil)
+			}
+			periodUpdater.OnPeriod = func(ratingPeriod *glicko.RatingPeriod, teams map[int]*ranking.Team) error {
+				logRatingPeriod(ratingPeriod, teams, standings)
+				return nil
+			}
+
+			server := api.BuildServer(func() ([]*glicko.RatingPeriod, map[int]*ranking.Team, error) {
+				periods, err := db.Periods()
+				if err != nil {
+					return nil, nil, err
+				}
+				teams, err := db.Teams()
+				return periods, teams, err
+			})
+			server.Leaderboard = *inputParams.leaderboard()
+			server.ConservativeK = inputParams.ConservativeK
+			server.DefaultRating = inputParams.defaultRating()
+			server.Resolver = resolver
+
+			daemon := updater.BuildDaemon(periodUpdater, interval)
+			daemon.OnRun = func(run *updater.Run, periods []*glicko.RatingPeriod) {
+				if run.Error != "" {
+					log.Printf("Run failed, retrying in %s: %s", interval, run.Error)
+					return
+				}
+				log.Printf("%d new period(s) calculated.", run.Periods)
+				if inputParams.Addr == "" || len(periods) == 0 {
+					return
+				}
+				if err := server.Reload(); err != nil {
+					log.Printf("Reloading the API failed, still serving the previous periods: %v", err)
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+			defer stop()
+
+			var httpServer *http.Server
+			if inputParams.Addr != "" {
+				// The stored periods are served while the first run goes.
+				if err := server.Reload(); err != nil {
+					return err
+				}
+
+				mux := http.NewServeMux()
+				mux.Handle("GET /health", daemon)
+				mux.Handle("/api/", server.Handler())
+				httpServer = &http.Server{Addr: inputParams.Addr, Handler: mux}
+				go func() {
+					log.Printf("Serving /health and the API on %s.", inputParams.Addr)
+					if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+						log.Printf("HTTP server failed: %v", err)
+						stop()
+					}
+				}()
+			}
+
+			log.Printf("Running every %s.", interval)
+			daemon.Run(ctx)
+			log.Printf("Shutting down.")
+
+			if httpServer != nil {
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				return httpServer.Shutdown(shutdownCtx)
+			}
+			return nil
+		},
+	}
+}
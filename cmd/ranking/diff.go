@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/urfave/cli"
+)
+
+// diffMovers is the amount of Teams listed on each side of the biggest movers.
+const diffMovers = 5
+
+// diffCommand builds the command that compares the rankings of two moments.
+func diffCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "diff",
+		Usage: "Compare the leaderboards at the end of two periods or dates: rank changes, rating deltas, new entrants, Teams that dropped out and the biggest risers and fallers.",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "from",
+				Usage:       "Id of a period, or RFC3339 or YYYY-MM-DD for the end of that day.",
+				Destination: &inputParams.DiffFrom,
+			},
+			cli.StringFlag{
+				Name:        "to",
+				Usage:       "Id of a period, or RFC3339 or YYYY-MM-DD for the end of that day (defaults to the end_date).",
+				Destination: &inputParams.DiffTo,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+			if inputParams.DiffFrom == "" {
+				return fmt.Errorf("diff needs --from")
+			}
+
+			format, err := inputParams.format()
+			if err != nil {
+				return err
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			periods, teams, err := inputParams.loadPeriods(parsedStartDate, parsedEndDate, resolver)
+			if err != nil {
+				return err
+			}
+
+			from, err := diffMoment("from", inputParams.DiffFrom, periods, parsedEndDate)
+			if err != nil {
+				return err
+			}
+			to := parsedEndDate
+			if inputParams.DiffTo != "" {
+				if to, err = diffMoment("to", inputParams.DiffTo, periods, parsedEndDate); err != nil {
+					return err
+				}
+			}
+			if !to.After(from) {
+				return fmt.Errorf("--to must be after --from")
+			}
+
+			leaderboard := inputParams.leaderboard()
+			diff := output.Compare(
+				leaderboard.Apply(inputParams.ranking(periods, teams, from)),
+				leaderboard.Apply(inputParams.ranking(periods, teams, to)),
+				diffMovers,
+			)
+			diff.From, diff.To = from, to
+
+			return inputParams.writeOutput(func(w io.Writer) error {
+				return output.WriteDiff(w, format, diff)
+			})
+		},
+	}
+}
+
+// diffMoment parses the value of --from or --to: the id of a period stands
+// for its end date.
+func diffMoment(flag, value string, periods []*glicko.RatingPeriod, endDate time.Time) (time.Time, error) {
+	if id, err := strconv.Atoi(value); err == nil {
+		for _, ratingPeriod := range periods {
+			if ratingPeriod.ID == id {
+				return ratingPeriod.EndDate, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("--%s: unknown period %d", flag, id)
+	}
+
+	at, err := parseDate(flag, value, true)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if at.After(endDate) {
+		return time.Time{}, fmt.Errorf("--%s must not be after the end_date", flag)
+	}
+	return at, nil
+}
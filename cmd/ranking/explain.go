@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/urfave/cli"
+)
+
+// explainCommand builds the command that breaks down the rating change of a
+// Team on a period.
+func explainCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "explain",
+		Usage: "Show how each Match of a Team on a period changed its rating, with the intermediate values of Glicko2 (JSON with --format json).",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "team",
+				Usage:       "Id or name of the Team.",
+				Destination: &inputParams.Team,
+			},
+			cli.IntFlag{
+				Name:        "period",
+				Usage:       "Id of the period (defaults to the last one the Team played).",
+				Destination: &inputParams.Period,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+			if inputParams.Team == "" {
+				return fmt.Errorf("explain needs --team")
+			}
+
+			format, err := inputParams.format()
+			if err != nil {
+				return err
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			periods, teams, err := inputParams.loadPeriods(parsedStartDate, parsedEndDate, resolver)
+			if err != nil {
+				return err
+			}
+
+			teamID, err := ranking.FindTeam(inputParams.Team, teams)
+			if err != nil {
+				return err
+			}
+			teamID = resolver.Resolve(teamID, parsedEndDate)
+
+			var ratingPeriod *glicko.RatingPeriod
+			var explanation *glicko.Explanation
+			for _, period := range periods {
+				if inputParams.Period != 0 && period.ID != inputParams.Period {
+					continue
+				}
+				if periodExplanation := period.Explain(teamID); periodExplanation != nil {
+					ratingPeriod, explanation = period, periodExplanation
+				}
+			}
+			if explanation == nil {
+				if inputParams.Period != 0 {
+					return fmt.Errorf("%s has no Matches on period %d", teamName(teams, teamID), inputParams.Period)
+				}
+				return fmt.Errorf("%s has no rated periods", teamName(teams, teamID))
+			}
+
+			report := output.BuildExplanationReport(ratingPeriod, explanation, teams)
+			return inputParams.writeOutput(func(w io.Writer) error {
+				return output.WriteExplanationReport(w, format, report)
+			})
+		},
+	}
+}
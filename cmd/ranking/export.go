@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/urfave/cli"
+)
+
+// exportCommand builds the command that writes the calculated periods and the
+// latest ratings to a snapshot file.
+func exportCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "export",
+		Usage: "Write the calculated periods and the latest ratings to the --output snapshot file (gob if it ends with .gob, JSON otherwise).",
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+
+			if inputParams.Output == "" {
+				return fmt.Errorf("export needs --output")
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			periods, _, err := inputParams.loadPeriods(parsedStartDate, parsedEndDate, resolver)
+			if err != nil {
+				return err
+			}
+
+			ledger := &glicko.Ledger{Periods: periods, Ratings: latestRatings(periods)}
+			if err := writeSnapshot(inputParams.Output, glicko.SnapshotLedger(ledger)); err != nil {
+				return err
+			}
+
+			log.Printf("%d period(s) exported to %s.\n", len(periods), inputParams.Output)
+			return nil
+		},
+	}
+}
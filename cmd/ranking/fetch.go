@@ -0,0 +1,39 @@
+package main
+
+import (
+	"log"
+
+	"github.com/urfave/cli"
+)
+
+// fetchCommand builds the command that downloads the Matches of the source
+// into the database, without rating them.
+func fetchCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "fetch",
+		Usage: "Download the Matches between start_date and end_date from the source into the database (requires --database).",
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+
+			db, err := inputParams.openStore("fetch")
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			matches, err := inputParams.fetchMatches(parsedStartDate, parsedEndDate)
+			if err != nil {
+				return err
+			}
+			if err := db.SaveMatches(matches); err != nil {
+				return err
+			}
+
+			log.Printf("%d match(es) saved.\n", len(matches))
+			return nil
+		},
+	}
+}
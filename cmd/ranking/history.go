@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/history"
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/urfave/cli"
+)
+
+// historyCommand builds the command that shows the state of a Team at a
+// specific moment.
+func historyCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "history",
+		Usage: "Show the rating, RD, volatility and rank of a Team at a specific moment.",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "team",
+				Usage:       "Id or name of the Team.",
+				Destination: &inputParams.Team,
+			},
+			cli.StringFlag{
+				Name:        "at",
+				Usage:       "Moment of the query, RFC3339 or YYYY-MM-DD for the end of that day (defaults to the end_date).",
+				Destination: &inputParams.At,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedAt, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+			if inputParams.At != "" {
+				if parsedAt, err = parseDate("at", inputParams.At, true); err != nil {
+					return err
+				}
+			}
+			if inputParams.Team == "" {
+				return fmt.Errorf("history needs --team")
+			}
+
+			format, err := inputParams.format()
+			if err != nil {
+				return err
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			periods, teams, err := inputParams.loadPeriods(parsedStartDate, parsedAt, resolver)
+			if err != nil {
+				return err
+			}
+
+			teamID, err := ranking.FindTeam(inputParams.Team, teams)
+			if err != nil {
+				return err
+			}
+			teamID = resolver.Resolve(teamID, parsedAt)
+
+			snapshot := history.BuildHistory(periods).RatingAt(teamID, parsedAt)
+			if snapshot == nil {
+				return fmt.Errorf("%s has no rating at %s", teamName(teams, teamID), parsedAt.Format(time.RFC3339))
+			}
+
+			report := output.BuildSnapshotReport(snapshot, teams)
+			return inputParams.writeOutput(func(w io.Writer) error {
+				return output.WriteSnapshotReport(w, format, report)
+			})
+		},
+	}
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/internal/store"
+	"github.com/augustoccesar/go-ranking/internal/updater"
+	"github.com/urfave/cli"
+)
+
+// leagueCommand builds the command with the subcommands to manage a league
+// by hand: its Teams and the results of its Matches.
+func leagueCommand(inputParams *InputParams) cli.Command {
+	matchFlags := []cli.Flag{
+		cli.StringFlag{
+			Name:        "home",
+			Usage:       "Id or name of the home Team.",
+			Destination: &inputParams.MatchHome,
+		},
+		cli.StringFlag{
+			Name:        "away",
+			Usage:       "Id or name of the away Team.",
+			Destination: &inputParams.MatchAway,
+		},
+		cli.StringFlag{
+			Name:        "winner",
+			Usage:       "home, away, tie or the id or name of one of the Teams (derived from --score when not given).",
+			Destination: &inputParams.MatchWinner,
+		},
+		cli.StringFlag{
+			Name:        "score",
+			Usage:       "Final score, home first, like 2-1.",
+			Destination: &inputParams.MatchScore,
+		},
+		cli.StringFlag{
+			Name:        "at",
+			Usage:       "When the Match started, RFC3339 or YYYY-MM-DD (defaults to now).",
+			Destination: &inputParams.MatchAt,
+		},
+	}
+
+	return cli.Command{
+		Name:  "league",
+		Usage: "Manage a league by hand on the --database: register Teams, record, edit or void results and list the Matches not rated yet. Changes on closed periods recompute the history from them. Use --source manual to rate only these Matches.",
+		Subcommands: []cli.Command{
+			{
+				Name:      "add-team",
+				Usage:     "Register a Team.",
+				ArgsUsage: "<name>",
+				Action: func(c *cli.Context) error {
+					if _, _, err := inputParams.dates(); err != nil {
+						return err
+					}
+					name := strings.TrimSpace(strings.Join(c.Args(), " "))
+					if name == "" {
+						return fmt.Errorf("add-team needs the name of the Team")
+					}
+
+					db, err := inputParams.openStore("league")
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					teams, err := db.Teams()
+					if err != nil {
+						return err
+					}
+					if _, err := ranking.FindTeam(name, teams); err == nil {
+						return fmt.Errorf("team %q already exists", name)
+					}
+
+					team, err := db.CreateTeam(name)
+					if err != nil {
+						return err
+					}
+					log.Printf("Team %s registered with id %d.", team.Name, team.ID)
+					return nil
+				},
+			},
+			{
+				Name:  "record",
+				Usage: "Record the result of a Match.",
+				Flags: matchFlags,
+				Action: func(c *cli.Context) error {
+					return inputParams.changeMatch(func(db store.Store, teams map[int]*ranking.Team) (*ranking.Match, time.Time, error) {
+						if inputParams.MatchHome == "" || inputParams.MatchAway == "" {
+							return nil, time.Time{}, fmt.Errorf("record needs --home and --away")
+						}
+						if inputParams.MatchWinner == "" && inputParams.MatchScore == "" {
+							return nil, time.Time{}, fmt.Errorf("record needs --winner or --score")
+						}
+
+						match := &ranking.Match{StartTime: time.Now().UTC()}
+						if err := inputParams.applyMatchFlags(match, teams); err != nil {
+							return nil, time.Time{}, err
+						}
+						if err := db.CreateMatch(match); err != nil {
+							return nil, time.Time{}, err
+						}
+						log.Printf("Match %d recorded: %s.", match.ID, formatResult(match))
+						return match, match.StartTime, nil
+					})
+				},
+			},
+			{
+				Name:      "edit",
+				Usage:     "Change the Teams, result or time of a Match. Only the flags given change.",
+				ArgsUsage: "<match id>",
+				Flags:     matchFlags,
+				Action: func(c *cli.Context) error {
+					return inputParams.changeMatch(func(db store.Store, teams map[int]*ranking.Team) (*ranking.Match, time.Time, error) {
+						match, err := storedMatch(db, c.Args().First())
+						if err != nil {
+							return nil, time.Time{}, err
+						}
+						previousStartTime := match.StartTime
+
+						if err := inputParams.applyMatchFlags(match, teams); err != nil {
+							return nil, time.Time{}, err
+						}
+						if err := db.SaveMatches([]*ranking.Match{match}); err != nil {
+							return nil, time.Time{}, err
+						}
+						log.Printf("Match %d changed: %s.", match.ID, formatResult(match))
+
+						if previousStartTime.Before(match.StartTime) {
+							return match, previousStartTime, nil
+						}
+						return match, match.StartTime, nil
+					})
+				},
+			},
+			{
+				Name:      "void",
+				Usage:     "Void a Match, so it is never rated.",
+				ArgsUsage: "<match id>",
+				Action: func(c *cli.Context) error {
+					return inputParams.changeMatch(func(db store.Store, teams map[int]*ranking.Team) (*ranking.Match, time.Time, error) {
+						match, err := storedMatch(db, c.Args().First())
+						if err != nil {
+							return nil, time.Time{}, err
+						}
+						if err := db.VoidMatch(match.ID); err != nil {
+							return nil, time.Time{}, err
+						}
+						log.Printf("Match %d voided: %s.", match.ID, formatResult(match))
+						return match, match.StartTime, nil
+					})
+				},
+			},
+			{
+				Name:  "pending",
+				Usage: "List the stored Matches since the start_date that weren't rated yet, on the --format given.",
+				Action: func(c *cli.Context) error {
+					parsedStartDate, parsedEndDate, err := inputParams.dates()
+					if err != nil {
+						return err
+					}
+					format, err := inputParams.format()
+					if err != nil {
+						return err
+					}
+
+					db, err := inputParams.openStore("league")
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					stored, err := db.Matches(parsedStartDate, parsedEndDate)
+					if err != nil {
+						return err
+					}
+					rated, err := db.RatedMatchIDs()
+					if err != nil {
+						return err
+					}
+
+					pending := []*ranking.Match{}
+					for _, match := range stored {
+						if !rated[match.ID] {
+							pending = append(pending, match)
+						}
+					}
+					return inputParams.writeOutput(func(w io.Writer) error {
+						return writePendingMatches(w, format, pending)
+					})
+				},
+			},
+		},
+	}
+}
+
+// changeMatch opens the database, applies a change to a Match and, when the
+// Match is on a period already stored, recomputes the history from the
+// moment returned.
+func (p *InputParams) changeMatch(change func(db store.Store, teams map[int]*ranking.Team) (*ranking.Match, time.Time, error)) error {
+	parsedStartDate, parsedEndDate, err := p.dates()
+	if err != nil {
+		return err
+	}
+	format, err := p.format()
+	if err != nil {
+		return err
+	}
+
+	resolver, err := p.resolver()
+	if err != nil {
+		return err
+	}
+
+	db, err := p.openStore("league")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	teams, err := db.Teams()
+	if err != nil {
+		return err
+	}
+
+	match, changedAt, err := change(db, teams)
+	if err != nil {
+		return err
+	}
+
+	periodUpdater := updater.BuildUpdater(db, nil, parsedStartDate, p.PeriodDuration, resolver)
+	periodUpdater.SystemConstant = p.Tau
+	periodUpdater.DefaultRating = p.defaultRating()
+	periodUpdater.Parallel = p.Parallel
+	recomputation, err := periodUpdater.Amend(changedAt, parsedEndDate, fmt.Sprintf("match %d changed", match.ID))
+	if err != nil {
+		return err
+	}
+	if recomputation == nil {
+		return nil
+	}
+	return p.writeOutput(func(w io.Writer) error {
+		return output.WriteRecomputationReport(w, format, output.BuildRecomputationReport(recomputation, teams))
+	})
+}
+
+// applyMatchFlags changes the Match with the flags given.
+func (p *InputParams) applyMatchFlags(match *ranking.Match, teams map[int]*ranking.Team) error {
+	if p.MatchHome != "" {
+		id, err := ranking.FindTeam(p.MatchHome, teams)
+		if err != nil {
+			return err
+		}
+		match.Home = teams[id]
+	}
+	if p.MatchAway != "" {
+		id, err := ranking.FindTeam(p.MatchAway, teams)
+		if err != nil {
+			return err
+		}
+		match.Away = teams[id]
+	}
+	if match.Home.ID == match.Away.ID {
+		return fmt.Errorf("a Team can't play against itself")
+	}
+
+	if p.MatchAt != "" {
+		at, err := parseDate("at", p.MatchAt, false)
+		if err != nil {
+			return err
+		}
+		match.StartTime = at
+	}
+	if match.StartTime.After(time.Now()) {
+		return fmt.Errorf("invalid --at %s: results can't be in the future", match.StartTime.Format(time.RFC3339))
+	}
+
+	if p.MatchScore != "" {
+		var err error
+		if match.HomeScore, match.AwayScore, err = parseScore(p.MatchScore); err != nil {
+			return err
+		}
+		if p.MatchWinner == "" {
+			match.Winner = scoreWinner(match).Winner
+		}
+	}
+
+	if p.MatchWinner != "" {
+		switch strings.ToLower(p.MatchWinner) {
+		case "home":
+			match.Winner = match.Home
+		case "away":
+			match.Winner = match.Away
+		case "tie":
+			match.Winner = nil
+		default:
+			id, err := ranking.FindTeam(p.MatchWinner, teams)
+			if err != nil || (id != match.Home.ID && id != match.Away.ID) {
+				return fmt.Errorf("invalid --winner %q: expected home, away, tie or one of the Teams", p.MatchWinner)
+			}
+			match.Winner = teams[id]
+		}
+		if p.MatchScore != "" && match.WinnerID() != scoreWinner(match).WinnerID() {
+			return fmt.Errorf("--winner %q doesn't match --score %s", p.MatchWinner, p.MatchScore)
+		}
+	}
+
+	// Changing the Teams must keep the Winner one of them.
+	if match.Winner != nil && match.Winner.ID != match.Home.ID && match.Winner.ID != match.Away.ID {
+		return fmt.Errorf("the winner %s doesn't play the Match, give --winner too", match.Winner.Name)
+	}
+	return nil
+}
+
+func storedMatch(db store.Store, value string) (*ranking.Match, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("expected the id of a Match, got %q", value)
+	}
+	match, err := db.Match(id)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		return nil, fmt.Errorf("match %d not found", id)
+	}
+	return match, nil
+}
+
+// parseScore parses a score like 2-1.
+func parseScore(value string) (home, away int, err error) {
+	parts := strings.Split(value, "-")
+	if len(parts) == 2 {
+		home, homeErr := strconv.Atoi(strings.TrimSpace(parts[0]))
+		away, awayErr := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if homeErr == nil && awayErr == nil && home >= 0 && away >= 0 {
+			return home, away, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid --score %q: expected the home and away scores like 2-1", value)
+}
+
+// scoreWinner returns the Match with the Winner given by its score.
+func scoreWinner(match *ranking.Match) *ranking.Match {
+	scored := *match
+	switch {
+	case match.HomeScore > match.AwayScore:
+		scored.Winner = match.Home
+	case match.AwayScore > match.HomeScore:
+		scored.Winner = match.Away
+	default:
+		scored.Winner = nil
+	}
+	return &scored
+}
+
+func formatResult(match *ranking.Match) string {
+	result := "tie"
+	if match.Winner != nil {
+		result = match.Winner.Name + " won"
+	}
+	return fmt.Sprintf("%s %d-%d %s (%s) on %s", match.Home.Name, match.HomeScore, match.AwayScore,
+		match.Away.Name, result, match.StartTime.Format(time.RFC3339))
+}
+
+// pendingMatch is the serializable form of a Match not rated yet.
+type pendingMatch struct {
+	ID        int       `json:"id"`
+	StartTime time.Time `json:"start_time"`
+	HomeID    int       `json:"home_id"`
+	Home      string    `json:"home"`
+	AwayID    int       `json:"away_id"`
+	Away      string    `json:"away"`
+	HomeScore int       `json:"home_score"`
+	AwayScore int       `json:"away_score"`
+	WinnerID  int       `json:"winner_id"` // -1 for a tie.
+}
+
+// writePendingMatches writes the Matches not rated yet, oldest first, on the
+// given Format.
+func writePendingMatches(w io.Writer, format output.Format, matches []*ranking.Match) error {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].StartTime.Before(matches[j].StartTime)
+	})
+
+	switch format {
+	case output.FormatJSON:
+		pending := []*pendingMatch{}
+		for _, match := range matches {
+			pending = append(pending, &pendingMatch{
+				ID:        match.ID,
+				StartTime: match.StartTime,
+				HomeID:    match.Home.ID,
+				Home:      match.Home.Name,
+				AwayID:    match.Away.ID,
+				Away:      match.Away.Name,
+				HomeScore: match.HomeScore,
+				AwayScore: match.AwayScore,
+				WinnerID:  match.WinnerID(),
+			})
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(pending)
+	case output.FormatCSV:
+		writer := csv.NewWriter(w)
+		err := writer.Write([]string{"id", "start_time", "home_id", "home", "away_id", "away", "home_score", "away_score", "winner_id"})
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			err := writer.Write([]string{
+				strconv.Itoa(match.ID),
+				match.StartTime.Format(time.RFC3339),
+				strconv.Itoa(match.Home.ID),
+				match.Home.Name,
+				strconv.Itoa(match.Away.ID),
+				match.Away.Name,
+				strconv.Itoa(match.HomeScore),
+				strconv.Itoa(match.AwayScore),
+				strconv.Itoa(match.WinnerID()),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case output.FormatMarkdown:
+		lines := []string{
+			"| ID | Start | Home | Score | Away | Winner |",
+			"| ---: | --- | --- | :---: | --- | --- |",
+		}
+		for _, match := range matches {
+			lines = append(lines, fmt.Sprintf("| %d | %s | %s | %d-%d | %s | %s |", match.ID,
+				match.StartTime.Format(time.RFC3339), escapeMarkdown(match.Home.Name), match.HomeScore,
+				match.AwayScore, escapeMarkdown(match.Away.Name), escapeMarkdown(pendingWinner(match))))
+		}
+		_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+		return err
+	case output.FormatTable:
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "ID\tSTART\tHOME\tSCORE\tAWAY\tWINNER")
+		for _, match := range matches {
+			fmt.Fprintf(table, "%d\t%s\t%s\t%d-%d\t%s\t%s\n", match.ID, match.StartTime.Format(time.RFC3339),
+				match.Home.Name, match.HomeScore, match.AwayScore, match.Away.Name, pendingWinner(match))
+		}
+		return table.Flush()
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func pendingWinner(match *ranking.Match) string {
+	if match.Winner == nil {
+		return "tie"
+	}
+	return match.Winner.Name
+}
+
+func escapeMarkdown(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePendingMatches(t *testing.T) {
+	home := &ranking.Team{ID: 1, Name: "Red | Dragons"}
+	away := &ranking.Team{ID: 2, Name: "Owls"}
+	matches := []*ranking.Match{
+		{ID: 11, Home: home, Away: away, HomeScore: 1, AwayScore: 1, StartTime: time.Date(2019, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{ID: 10, Home: home, Away: away, Winner: home, HomeScore: 2, AwayScore: 1, StartTime: time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, writePendingMatches(buffer, output.FormatJSON, matches))
+	pending := []*pendingMatch{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &pending))
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, 10, pending[0].ID)
+		assert.Equal(t, 1, pending[0].WinnerID)
+		assert.Equal(t, -1, pending[1].WinnerID)
+	}
+
+	buffer.Reset()
+	assert.Nil(t, writePendingMatches(buffer, output.FormatCSV, matches))
+	records, err := csv.NewReader(buffer).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "start_time", "home_id", "home", "away_id", "away", "home_score", "away_score", "winner_id"},
+		{"10", "2019-03-02T00:00:00Z", "1", "Red | Dragons", "2", "Owls", "2", "1", "1"},
+		{"11", "2019-03-09T00:00:00Z", "1", "Red | Dragons", "2", "Owls", "1", "1", "-1"},
+	}, records)
+
+	buffer.Reset()
+	assert.Nil(t, writePendingMatches(buffer, output.FormatMarkdown, matches))
+	assert.Contains(t, buffer.String(), "| 10 | 2019-03-02T00:00:00Z | Red \\| Dragons | 2-1 | Owls | Red \\| Dragons |\n")
+	assert.Contains(t, buffer.String(), "| 11 | 2019-03-09T00:00:00Z | Red \\| Dragons | 1-1 | Owls | tie |\n")
+
+	buffer.Reset()
+	assert.Nil(t, writePendingMatches(buffer, output.FormatTable, matches))
+	assert.Contains(t, buffer.String(), "ID  START")
+
+	assert.NotNil(t, writePendingMatches(buffer, output.Format("xml"), matches))
+}
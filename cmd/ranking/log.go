@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"
+
+	"github.com/augustoccesar/go-ranking/internal/lineage"
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// logRatingPeriod logs that the RatingPeriod was calculated and returns its
+// ranking, adding it to the Standings.
+func logRatingPeriod(ratingPeriod *glicko.RatingPeriod, teams map[int]*ranking.Team, standings *output.Standings) []*output.Row {
+	log.Printf("Period %d (%s - %s) calculated: %d match(es).\n", ratingPeriod.ID,
+		ratingPeriod.StartDate.Format("2006-01-02"), ratingPeriod.EndDate.Format("2006-01-02"), len(ratingPeriod.Matches))
+	return standings.Add(ratingPeriod, teams)
+}
+
+// logMergeSuggestions prints the pairs of Teams that look like the same
+// lineage, so they can be reviewed and added to the aliases file.
+func logMergeSuggestions(matches []*ranking.Match, resolver *lineage.Resolver) {
+	activities := map[int]*lineage.TeamActivity{}
+	for _, match := range matches {
+		for _, team := range []*ranking.Team{match.Home, match.Away} {
+			activity, ok := activities[team.ID]
+			if !ok {
+				activity = &lineage.TeamActivity{ID: team.ID, FirstSeen: match.StartTime}
+				activities[team.ID] = activity
+			}
+			activity.Name = team.Name
+			activity.LastSeen = match.StartTime
+		}
+	}
+
+	teams := []*lineage.TeamActivity{}
+	for _, activity := range activities {
+		teams = append(teams, activity)
+	}
+
+	log.Printf("Possible merges:\n")
+	for _, suggestion := range lineage.Suggest(teams, resolver) {
+		log.Printf("\t%s (%d) -> %s (%d) - name: %.2f, effective: %s\n",
+			suggestion.From.Name, suggestion.From.ID,
+			suggestion.To.Name, suggestion.To.ID,
+			suggestion.NameSimilarity,
+			suggestion.To.FirstSeen.Format("2006-01-02"),
+		)
+	}
+}
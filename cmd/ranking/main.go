@@ -0,0 +1,251 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/urfave/cli"
+)
+
+// AvailableSources contains the list of sources that the script supports. The
+// manual one has only the Matches entered with league on the --database.
+var AvailableSources = []string{"thescore", "manual"}
+
+func checkSource(source string) bool {
+	for _, availabeSource := range AvailableSources {
+		if availabeSource == source {
+			return true
+		}
+	}
+	return false
+}
+
+// InputParams holds the values of the flags, the global ones and the ones of
+// each command.
+type InputParams struct {
+	ConfigFile     string
+	Source         string
+	StartDate      string
+	EndDate        string
+	PeriodDuration int
+	AliasesFile    string
+	SuggestMerges  bool
+	FixtureDays    int
+	BestOf         int
+	Database       string
+	Tau            float64
+	DefaultRating  float64
+	DefaultRD      float64
+	DefaultSigma   float64
+	Parallel       bool
+	RecomputeFrom  string
+	Team           string
+	At             string
+	SnapshotFile   string
+	Live           bool
+	Format         string
+	Output         string
+	Top            int
+	MinMatches     int
+	MaxRD          float64
+	ActiveWithin   string
+	SortBy         string
+	ConservativeK  float64
+	ReportDir      string
+	DiffFrom       string
+	DiffTo         string
+	Addr           string
+	ReloadEvery    string
+	Interval       string
+	MatchHome      string
+	MatchAway      string
+	MatchWinner    string
+	MatchScore     string
+	MatchAt        string
+	Period         int
+
+	// Parsed by validate.
+	startDate    time.Time
+	endDate      time.Time
+	activeWithin time.Duration
+}
+
+func main() {
+	app := buildApp(&InputParams{})
+
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// buildApp builds the CLI with its flags bound to the InputParams.
+func buildApp(inputParams *InputParams) *cli.App {
+	app := cli.NewApp()
+	app.Name = "Ranking CLI."
+	app.Usage = ""
+
+	defaltEndTime := time.Now().UTC()
+	defaultStartTime := defaltEndTime.AddDate(0, -1, 0)
+
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:        "config",
+			Usage:       "YAML, TOML or JSON file with the values of the global flags. Flags given on the command line override it.",
+			Destination: &inputParams.ConfigFile,
+		},
+		cli.StringFlag{
+			Name:        "source",
+			Value:       "thescore",
+			Usage:       "Source from where the system will fetch data: thescore, or manual for only the Matches entered with league.",
+			Destination: &inputParams.Source,
+		},
+		cli.StringFlag{
+			Name:        "start_date",
+			Value:       defaultStartTime.Format(time.RFC3339),
+			Usage:       "Date that the system will use as base to look for data (RFC3339 or YYYY-MM-DD).",
+			Destination: &inputParams.StartDate,
+		},
+		cli.StringFlag{
+			Name:        "end_date",
+			Value:       defaltEndTime.Format(time.RFC3339),
+			Usage:       "Limit date of the data (RFC3339, or YYYY-MM-DD for the end of that day).",
+			Destination: &inputParams.EndDate,
+		},
+		cli.IntFlag{
+			Name:        "period_duration",
+			Value:       7,
+			Usage:       "Length in days of the Rating Period.",
+			Destination: &inputParams.PeriodDuration,
+		},
+		cli.StringFlag{
+			Name:        "aliases",
+			Usage:       "JSON file with Team aliases (old id -> new id) to carry ratings across rebrands.",
+			Destination: &inputParams.AliasesFile,
+		},
+		cli.StringFlag{
+			Name:        "database",
+			Usage:       "SQLite file where Teams, Matches and the results of each period are persisted.",
+			Destination: &inputParams.Database,
+		},
+		cli.Float64Flag{
+			Name:        "tau",
+			Value:       0.5,
+			Usage:       "Glicko2 system constant (τ), constrains the change of volatility over time.",
+			Destination: &inputParams.Tau,
+		},
+		cli.Float64Flag{
+			Name:        "default_rating",
+			Value:       1500,
+			Usage:       "Rating of the Teams on their first period.",
+			Destination: &inputParams.DefaultRating,
+		},
+		cli.Float64Flag{
+			Name:        "default_rd",
+			Value:       350,
+			Usage:       "Rating deviation of the Teams on their first period.",
+			Destination: &inputParams.DefaultRD,
+		},
+		cli.Float64Flag{
+			Name:        "default_volatility",
+			Value:       0.06,
+			Usage:       "Volatility of the Teams on their first period.",
+			Destination: &inputParams.DefaultSigma,
+		},
+		cli.BoolFlag{
+			Name:        "parallel",
+			Usage:       "Calculate the Teams of each period on one goroutine per CPU (same results, faster on big periods).",
+			Destination: &inputParams.Parallel,
+		},
+		cli.StringFlag{
+			Name:        "snapshot",
+			Usage:       "File where the periods and latest ratings are written after rating (gob if it ends with .gob, JSON otherwise).",
+			Destination: &inputParams.SnapshotFile,
+		},
+		cli.StringFlag{
+			Name:        "format",
+			Value:       "table",
+			Usage:       "Format of the rankings printed by rate, update and show: table, json, csv or markdown.",
+			Destination: &inputParams.Format,
+		},
+		cli.StringFlag{
+			Name:        "output",
+			Usage:       "File where the output is written (stdout by default, logs always go to stderr).",
+			Destination: &inputParams.Output,
+		},
+		cli.IntFlag{
+			Name:        "top",
+			Usage:       "Amount of ranked Teams printed on each period (0 for all).",
+			Destination: &inputParams.Top,
+		},
+		cli.IntFlag{
+			Name:        "min_matches",
+			Usage:       "Matches a Team must have played to be ranked, the others are listed as provisional.",
+			Destination: &inputParams.MinMatches,
+		},
+		cli.Float64Flag{
+			Name:        "max_rd",
+			Usage:       "Maximum RD of a ranked Team (0 for no limit), the others are listed as provisional.",
+			Destination: &inputParams.MaxRD,
+		},
+		cli.StringFlag{
+			Name:        "active_within",
+			Usage:       "Maximum time since the last period a ranked Team played, like 30d or 720h (empty for no limit).",
+			Destination: &inputParams.ActiveWithin,
+		},
+		cli.StringFlag{
+			Name:        "sort",
+			Value:       "rating",
+			Usage:       "Key by which the Teams are ranked: rating, conservative (rating - k RD) or delta.",
+			Destination: &inputParams.SortBy,
+		},
+		cli.Float64Flag{
+			Name:        "conservative_k",
+			Value:       glicko.DefaultConservativeK,
+			Usage:       "Amount of RDs subtracted from the rating on the conservative rating.",
+			Destination: &inputParams.ConservativeK,
+		},
+		cli.BoolFlag{
+			Name:        "suggest_merges",
+			Usage:       "Print Teams that are likely the same lineage but are not aliased.",
+			Destination: &inputParams.SuggestMerges,
+		},
+	}
+
+	// A config that can't be fully loaded stops every command, before any of
+	// them runs with part of it applied.
+	app.Before = func(c *cli.Context) error {
+		if inputParams.ConfigFile == "" {
+			return nil
+		}
+		return loadConfig(c, inputParams.ConfigFile)
+	}
+
+	rate := rateCommand(inputParams)
+
+	// Without a command the app rates, as it did before having commands.
+	app.Action = rate.Action
+
+	app.Commands = []cli.Command{
+		fetchCommand(inputParams),
+		rate,
+		showCommand(inputParams),
+		teamCommand(inputParams),
+		predictCommand(inputParams),
+		exportCommand(inputParams),
+		updateCommand(inputParams),
+		recomputeCommand(inputParams),
+		historyCommand(inputParams),
+		explainCommand(inputParams),
+		reportCommand(inputParams),
+		diffCommand(inputParams),
+		serveCommand(inputParams),
+		daemonCommand(inputParams),
+		leagueCommand(inputParams),
+		configCommand(inputParams),
+	}
+
+	return app
+}
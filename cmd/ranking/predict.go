@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/history"
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/prediction"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/internal/spider/thescore"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/urfave/cli"
+)
+
+// predictCommand builds the command that predicts the scheduled Matches after
+// the end_date.
+func predictCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "predict",
+		Usage: "Predict the scheduled Matches of the next days based on the current ratings.",
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:        "days",
+				Value:       7,
+				Usage:       "How many days after the end_date to look for scheduled Matches.",
+				Destination: &inputParams.FixtureDays,
+			},
+			cli.IntFlag{
+				Name:        "best_of",
+				Value:       3,
+				Usage:       "Amount of maps of the series, used for the expected scorelines.",
+				Destination: &inputParams.BestOf,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+
+			if inputParams.FixtureDays <= 0 {
+				return fmt.Errorf("invalid --days %d: must be at least 1", inputParams.FixtureDays)
+			}
+			if inputParams.BestOf <= 0 || inputParams.BestOf%2 == 0 {
+				return fmt.Errorf("invalid --best_of %d: must be a positive odd number", inputParams.BestOf)
+			}
+
+			format, err := inputParams.format()
+			if err != nil {
+				return err
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			periods, _, err := inputParams.loadPeriods(parsedStartDate, parsedEndDate, resolver)
+			if err != nil {
+				return err
+			}
+			ratingHistory := history.BuildHistory(periods)
+
+			var fixtures []*ranking.Match
+			switch inputParams.Source {
+			case "thescore":
+				scheduled, err := thescore.FetchFixtures(parsedEndDate, parsedEndDate.AddDate(0, 0, inputParams.FixtureDays))
+				if err != nil {
+					return err
+				}
+				fixtures = ranking.FromTheScore(scheduled)
+			}
+
+			rating := func(teamID int, at time.Time) *glicko.Rating {
+				if snapshot := ratingHistory.RatingAt(resolver.Resolve(teamID, at), at); snapshot != nil {
+					return snapshot.Rating
+				}
+				return inputParams.defaultRating()
+			}
+			predictions, err := predictFixtures(fixtures, rating, inputParams.BestOf)
+			if err != nil {
+				return err
+			}
+			return inputParams.writeOutput(func(w io.Writer) error {
+				return output.WritePredictions(w, format, predictions)
+			})
+		},
+	}
+}
+
+// predictFixtures predicts the win probabilities and expected scorelines of
+// the scheduled Matches based on the rating of each Team by the start of the
+// Match, sorted by start time.
+func predictFixtures(fixtures []*ranking.Match, rating func(teamID int, at time.Time) *glicko.Rating, bestOf int) ([]*output.FixturePrediction, error) {
+	sort.SliceStable(fixtures, func(i, j int) bool {
+		return fixtures[i].StartTime.Before(fixtures[j].StartTime)
+	})
+
+	predictions := []*output.FixturePrediction{}
+	for _, fixture := range fixtures {
+		homeRating := rating(fixture.Home.ID, fixture.StartTime)
+		awayRating := rating(fixture.Away.ID, fixture.StartTime)
+
+		predicted, err := prediction.Predict(homeRating, awayRating, bestOf)
+		if err != nil {
+			return nil, err
+		}
+		predictions = append(predictions, output.BuildFixturePrediction(fixture, homeRating, awayRating, predicted))
+	}
+
+	return predictions, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/urfave/cli"
+)
+
+// rateCommand builds the command that calculates the rating periods between
+// start_date and end_date. It is also the action of the app when no command
+// is given.
+func rateCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "rate",
+		Usage: "Calculate the rating periods between start_date and end_date (persisting them when --database is set).",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:        "live",
+				Usage:       "Fetch the Matches from the source even when --database is set.",
+				Destination: &inputParams.Live,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+
+			format, err := inputParams.format()
+			if err != nil {
+				return err
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			rows := []*output.Row{}
+			standings := inputParams.standings()
+			ranker := inputParams.buildRanker(parsedStartDate, parsedEndDate, resolver)
+			ranker.OnPeriod = func(ratingPeriod *glicko.RatingPeriod) error {
+				rows = append(rows, logRatingPeriod(ratingPeriod, ranker.Teams, standings)...)
+				return nil
+			}
+
+			var matches []*ranking.Match
+			if inputParams.Database != "" {
+				db, err := inputParams.openStore("rate")
+				if err != nil {
+					return err
+				}
+				defer db.Close()
+
+				if inputParams.Live {
+					if matches, err = inputParams.fetchMatches(parsedStartDate, parsedEndDate); err != nil {
+						return err
+					}
+					if err := db.SaveMatches(matches); err != nil {
+						return err
+					}
+				} else if matches, err = db.Matches(parsedStartDate, parsedEndDate); err != nil {
+					return err
+				}
+
+				ranker.OnPeriod = func(ratingPeriod *glicko.RatingPeriod) error {
+					rows = append(rows, logRatingPeriod(ratingPeriod, ranker.Teams, standings)...)
+					if err := db.SavePeriod(ratingPeriod); err != nil {
+						return fmt.Errorf("%v: rate the same --start_date as the stored periods, use update to continue them or recompute to replace them", err)
+					}
+					return nil
+				}
+			} else if matches, err = inputParams.fetchMatches(parsedStartDate, parsedEndDate); err != nil {
+				return err
+			}
+
+			if _, err := ranker.Rate(matches); err != nil {
+				return err
+			}
+			if err := inputParams.writeRows(format, rows); err != nil {
+				return err
+			}
+
+			if inputParams.SnapshotFile != "" {
+				ledger := &glicko.Ledger{Periods: ranker.Periods, Ratings: ranker.Ratings}
+				if err := writeSnapshot(inputParams.SnapshotFile, glicko.SnapshotLedger(ledger)); err != nil {
+					return err
+				}
+			}
+
+			if inputParams.SuggestMerges {
+				logMergeSuggestions(matches, resolver)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/updater"
+	"github.com/urfave/cli"
+)
+
+// recomputeCommand builds the command that replays the stored Matches into a
+// new ratings version.
+func recomputeCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "recompute",
+		Usage: "Replay the stored Matches from a date with the current configuration into a new ratings version (requires --database).",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "from",
+				Usage:       "Date from where the periods are stale and must be recalculated (RFC3339 or YYYY-MM-DD).",
+				Destination: &inputParams.RecomputeFrom,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+			format, err := inputParams.format()
+			if err != nil {
+				return err
+			}
+			if inputParams.RecomputeFrom == "" {
+				return fmt.Errorf("recompute needs --from")
+			}
+			parsedFrom, err := parseDate("from", inputParams.RecomputeFrom, false)
+			if err != nil {
+				return err
+			}
+			if parsedFrom.After(parsedEndDate) {
+				return fmt.Errorf("--from (%s) must not be after --end_date (%s)",
+					parsedFrom.Format(time.RFC3339), parsedEndDate.Format(time.RFC3339))
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			db, err := inputParams.openStore("recompute")
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			periodUpdater := updater.BuildUpdater(db, nil, parsedStartDate, inputParams.PeriodDuration, resolver)
+			periodUpdater.SystemConstant = inputParams.Tau
+			periodUpdater.DefaultRating = inputParams.defaultRating()
+			periodUpdater.Parallel = inputParams.Parallel
+
+			description := fmt.Sprintf("τ=%g default=%g/%g/%g", inputParams.Tau,
+				inputParams.DefaultRating, inputParams.DefaultRD, inputParams.DefaultSigma)
+			recomputation, err := periodUpdater.Recompute(parsedFrom, parsedEndDate, description)
+			if err != nil {
+				return err
+			}
+
+			teams, err := db.Teams()
+			if err != nil {
+				return err
+			}
+			return inputParams.writeOutput(func(w io.Writer) error {
+				return output.WriteRecomputationReport(w, format, output.BuildRecomputationReport(recomputation, teams))
+			})
+		},
+	}
+}
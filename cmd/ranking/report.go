@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/augustoccesar/go-ranking/internal/report"
+	"github.com/urfave/cli"
+)
+
+// reportCommand builds the command that renders the computed periods as a
+// static HTML site.
+func reportCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "report",
+		Usage: "Render a self-contained HTML site with the leaderboard of each period, the biggest movers and a page with the rating chart of each Team.",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "out",
+				Usage:       "Directory of the site, created if missing.",
+				Destination: &inputParams.ReportDir,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+			if inputParams.ReportDir == "" {
+				return fmt.Errorf("report needs --out")
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			periods, teams, err := inputParams.loadPeriods(parsedStartDate, parsedEndDate, resolver)
+			if err != nil {
+				return err
+			}
+
+			closedPeriods := periods[:0:0]
+			for _, ratingPeriod := range periods {
+				if !ratingPeriod.EndDate.After(parsedEndDate) {
+					closedPeriods = append(closedPeriods, ratingPeriod)
+				}
+			}
+
+			title := fmt.Sprintf("Ranking %s - %s", parsedStartDate.Format("2006-01-02"), parsedEndDate.Format("2006-01-02"))
+			site := report.Build(title, closedPeriods, teams, inputParams.standings(), inputParams.leaderboard())
+			if err := site.Write(inputParams.ReportDir); err != nil {
+				return err
+			}
+
+			log.Printf("Report of %d period(s) and %d Team(s) written to %s.", len(site.Periods), len(site.Teams), inputParams.ReportDir)
+			return nil
+		},
+	}
+}
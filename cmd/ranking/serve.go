@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/api"
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/urfave/cli"
+)
+
+// serveCommand builds the command that serves the computed ratings as a JSON
+// API.
+func serveCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "serve",
+		Usage: "Serve the leaderboard, Teams, periods, head-to-heads and predictions as a read-only JSON API. The periods are reloaded on SIGHUP and every --reload_every.",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "addr",
+				Value:       ":8080",
+				Usage:       "Address to listen on.",
+				Destination: &inputParams.Addr,
+			},
+			cli.StringFlag{
+				Name:        "reload_every",
+				Usage:       "Reload the periods periodically, like 10m or 1d (disabled by default).",
+				Destination: &inputParams.ReloadEvery,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+
+			var reloadEvery time.Duration
+			if inputParams.ReloadEvery != "" {
+				if reloadEvery, err = output.ParseDuration(inputParams.ReloadEvery); err != nil || reloadEvery <= 0 {
+					return fmt.Errorf("invalid --reload_every %q: expected a positive duration like 10m or 1d", inputParams.ReloadEvery)
+				}
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			// Without --end_date every reload goes until that moment, so the
+			// periods calculated since the last one are served too.
+			followNow := !c.GlobalIsSet("end_date")
+			server := api.BuildServer(func() ([]*glicko.RatingPeriod, map[int]*ranking.Team, error) {
+				endDate := parsedEndDate
+				if followNow {
+					endDate = time.Now().UTC()
+				}
+				return inputParams.loadPeriods(parsedStartDate, endDate, resolver)
+			})
+			server.Leaderboard = *inputParams.leaderboard()
+			server.ConservativeK = inputParams.ConservativeK
+			server.DefaultRating = inputParams.defaultRating()
+			server.Resolver = resolver
+			if err := server.Reload(); err != nil {
+				return err
+			}
+			logReload(server)
+
+			reload := make(chan os.Signal, 1)
+			signal.Notify(reload, syscall.SIGHUP)
+			var tick <-chan time.Time
+			if reloadEvery > 0 {
+				tick = time.NewTicker(reloadEvery).C
+			}
+			go func() {
+				for {
+					select {
+					case <-reload:
+					case <-tick:
+					}
+					if err := server.Reload(); err != nil {
+						log.Printf("Reload failed, still serving the previous periods: %v", err)
+						continue
+					}
+					logReload(server)
+				}
+			}()
+
+			log.Printf("Serving the API on %s.", inputParams.Addr)
+			return http.ListenAndServe(inputParams.Addr, server.Handler())
+		},
+	}
+}
+
+func logReload(server *api.Server) {
+	status := server.Status()
+	log.Printf("Loaded %d period(s) and %d Team(s).", status.Periods, status.Teams)
+}
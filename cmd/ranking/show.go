@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/urfave/cli"
+)
+
+// showCommand builds the command that prints the leaderboard by the
+// end_date.
+func showCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "show",
+		Usage: "Show the leaderboard by the end_date (from the database when --database is set).",
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+
+			format, err := inputParams.format()
+			if err != nil {
+				return err
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			periods, teams, err := inputParams.loadPeriods(parsedStartDate, parsedEndDate, resolver)
+			if err != nil {
+				return err
+			}
+
+			return inputParams.writeRows(format, inputParams.ranking(periods, teams, parsedEndDate))
+		},
+	}
+}
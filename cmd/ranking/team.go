@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/augustoccesar/go-ranking/internal/history"
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/urfave/cli"
+)
+
+// teamCommand builds the command that follows the rating of a Team over the
+// periods.
+func teamCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:      "team",
+		Usage:     "Show the rating, RD, volatility, rank and Matches of a Team on each period, with a sparkline of its rating (JSON with --format json).",
+		ArgsUsage: "<id|name>",
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+
+			if !c.Args().Present() {
+				return fmt.Errorf("team needs the id or name of the Team")
+			}
+
+			format, err := inputParams.format()
+			if err != nil {
+				return err
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			periods, teams, err := inputParams.loadPeriods(parsedStartDate, parsedEndDate, resolver)
+			if err != nil {
+				return err
+			}
+
+			teamID, err := ranking.FindTeam(c.Args().First(), teams)
+			if err != nil {
+				return err
+			}
+			teamID = resolver.Resolve(teamID, parsedEndDate)
+
+			closedPeriods := periods[:0:0]
+			for _, ratingPeriod := range periods {
+				if !ratingPeriod.EndDate.After(parsedEndDate) {
+					closedPeriods = append(closedPeriods, ratingPeriod)
+				}
+			}
+
+			points := history.BuildHistory(closedPeriods).Trajectory(teamID)
+			report := output.BuildTeamReport(teamID, points, teams)
+			return inputParams.writeOutput(func(w io.Writer) error {
+				return output.WriteTeamReport(w, format, report)
+			})
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"log"
+
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/internal/updater"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/urfave/cli"
+)
+
+// updateCommand builds the command that calculates only the periods closed
+// since the last run.
+func updateCommand(inputParams *InputParams) cli.Command {
+	return cli.Command{
+		Name:  "update",
+		Usage: "Calculate and persist only the periods closed since the last run (requires --database).",
+		Action: func(c *cli.Context) error {
+			parsedStartDate, parsedEndDate, err := inputParams.dates()
+			if err != nil {
+				return err
+			}
+
+			format, err := inputParams.format()
+			if err != nil {
+				return err
+			}
+
+			fetch, err := inputParams.fetcher()
+			if err != nil {
+				return err
+			}
+
+			resolver, err := inputParams.resolver()
+			if err != nil {
+				return err
+			}
+
+			db, err := inputParams.openStore("update")
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			periodUpdater := updater.BuildUpdater(db, fetch, parsedStartDate, inputParams.PeriodDuration, resolver)
+			periodUpdater.SystemConstant = inputParams.Tau
+			periodUpdater.DefaultRating = inputParams.defaultRating()
+			periodUpdater.Parallel = inputParams.Parallel
+			// The stored periods count for the totals of the Teams.
+			standings := inputParams.standings()
+			storedPeriods, err := db.Periods()
+			if err != nil {
+				return err
+			}
+			for _, ratingPeriod := range storedPeriods {
+				standings.Add(ratingPeriod, nil)
+			}
+
+			rows := []*output.Row{}
+			periodUpdater.OnPeriod = func(ratingPeriod *glicko.RatingPeriod, teams map[int]*ranking.Team) error {
+				rows = append(rows, logRatingPeriod(ratingPeriod, teams, standings)...)
+				return nil
+			}
+
+			periods, err := periodUpdater.Update(parsedEndDate)
+			if err != nil {
+				return err
+			}
+			log.Printf("%d new period(s) calculated.\n", len(periods))
+			return inputParams.writeRows(format, rows)
+		},
+	}
+}
@@ -1,6 +1,25 @@
 module github.com/augustoccesar/go-ranking
 
+go 1.22
+
 require (
-	github.com/stretchr/testify v1.3.0
+	github.com/BurntSushi/toml v1.4.0
+	github.com/stretchr/testify v1.4.0
 	github.com/urfave/cli v1.20.0
+	gopkg.in/yaml.v2 v2.2.2
+	modernc.org/sqlite v1.34.5
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/sys v0.22.0 // indirect
+	modernc.org/libc v1.55.3 // indirect
+	modernc.org/mathutil v1.6.0 // indirect
+	modernc.org/memory v1.8.0 // indirect
 )
@@ -0,0 +1,482 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/history"
+	"github.com/augustoccesar/go-ranking/internal/lineage"
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/prediction"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// Limits of the pagination of the leaderboard.
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// Loader loads the calculated RatingPeriods and the Teams served by the API.
+type Loader func() ([]*glicko.RatingPeriod, map[int]*ranking.Team, error)
+
+// Server is the struct that serves the computed ratings as a read-only JSON
+// API. The data is loaded once by Reload and kept in memory until the next
+// call, so requests never see a half loaded state.
+type Server struct {
+	Leaderboard   output.Leaderboard // Default filters, overridden by the query.
+	ConservativeK float64
+	DefaultRating *glicko.Rating // Rating of Teams without periods on predictions.
+	Resolver      *lineage.Resolver
+
+	loader Loader
+	mutex  sync.RWMutex
+	data   *data
+}
+
+// data is the state loaded by the Loader.
+type data struct {
+	periods  []*glicko.RatingPeriod // Oldest first.
+	teams    map[int]*ranking.Team
+	history  *history.History
+	loadedAt time.Time
+}
+
+// BuildServer builds a Server of the Loader, without loading it.
+func BuildServer(loader Loader) *Server {
+	return &Server{
+		ConservativeK: glicko.DefaultConservativeK,
+		DefaultRating: glicko.BuildDefaultRating(),
+		loader:        loader,
+		data:          &data{teams: map[int]*ranking.Team{}, history: history.BuildHistory(nil)},
+	}
+}
+
+// Reload loads the periods again. On error the previous data keeps being
+// served.
+func (s *Server) Reload() error {
+	periods, teams, err := s.loader()
+	if err != nil {
+		return err
+	}
+
+	h := history.BuildHistory(periods)
+	loaded := &data{
+		periods:  h.Periods(),
+		teams:    teams,
+		history:  h,
+		loadedAt: time.Now().UTC(),
+	}
+
+	s.mutex.Lock()
+	s.data = loaded
+	s.mutex.Unlock()
+	return nil
+}
+
+// Status is the struct returned by the status endpoint.
+type Status struct {
+	Periods  int       `json:"periods"`
+	Teams    int       `json:"teams"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// Status returns what is being served.
+func (s *Server) Status() *Status {
+	d := s.snapshot()
+	return &Status{Periods: len(d.periods), Teams: len(d.teams), LoadedAt: d.loadedAt}
+}
+
+func (s *Server) snapshot() *data {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.data
+}
+
+// Handler returns the http.Handler of the API:
+//
+//	GET /api/status
+//	GET /api/leaderboard?at=&top=&min_matches=&max_rd=&active_within=&sort=&offset=&limit=
+//	GET /api/periods
+//	GET /api/teams
+//	GET /api/teams/{team}
+//	GET /api/head-to-head?home=&away=
+//	GET /api/predict?home=&away=&best_of=&at=
+//
+// Teams are given by id or name. Nothing changes the data: it is only
+// reloaded by calling Reload.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("GET /api/leaderboard", s.handleLeaderboard)
+	mux.HandleFunc("GET /api/periods", s.handlePeriods)
+	mux.HandleFunc("GET /api/teams", s.handleTeams)
+	mux.HandleFunc("GET /api/teams/{team}", s.handleTeam)
+	mux.HandleFunc("GET /api/head-to-head", s.handleHeadToHead)
+	mux.HandleFunc("GET /api/predict", s.handlePredict)
+	return mux
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Status())
+}
+
+// LeaderboardPage is the struct returned by the leaderboard endpoint.
+type LeaderboardPage struct {
+	At     time.Time     `json:"at"`
+	Total  int           `json:"total"` // Rows before the pagination.
+	Offset int           `json:"offset"`
+	Limit  int           `json:"limit"`
+	Rows   []*output.Row `json:"rows"`
+}
+
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	d := s.snapshot()
+	query := &queryParser{values: r.URL.Query()}
+
+	at := query.moment("at", d.lastEndDate())
+	leaderboard := s.Leaderboard
+	leaderboard.Top = query.integer("top", leaderboard.Top)
+	leaderboard.MinMatches = query.integer("min_matches", leaderboard.MinMatches)
+	leaderboard.MaxRD = query.float("max_rd", leaderboard.MaxRD)
+	leaderboard.ActiveWithin = query.duration("active_within", leaderboard.ActiveWithin)
+	leaderboard.SortBy = query.sortKey("sort", leaderboard.SortBy)
+	offset := query.integer("offset", 0)
+	limit := query.integer("limit", DefaultLimit)
+	if query.err == nil && (limit < 1 || limit > MaxLimit) {
+		query.err = fmt.Errorf("invalid limit %d: must be between 1 and %d", limit, MaxLimit)
+	}
+	if query.err != nil {
+		writeError(w, http.StatusBadRequest, query.err)
+		return
+	}
+
+	standings := output.BuildStandings()
+	standings.ConservativeK = s.ConservativeK
+	rows := leaderboard.Apply(standings.RankingAt(d.history, d.teams, at))
+
+	page := &LeaderboardPage{At: at, Total: len(rows), Offset: offset, Limit: limit, Rows: []*output.Row{}}
+	if offset < len(rows) {
+		end := offset + limit
+		if end > len(rows) {
+			end = len(rows)
+		}
+		page.Rows = rows[offset:end]
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// PeriodSummary is the struct that describes a period on the periods endpoint.
+type PeriodSummary struct {
+	ID        int       `json:"id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Matches   int       `json:"matches"`
+	Teams     int       `json:"teams"`
+}
+
+func (s *Server) handlePeriods(w http.ResponseWriter, r *http.Request) {
+	summaries := []*PeriodSummary{}
+	for _, ratingPeriod := range s.snapshot().periods {
+		summary := &PeriodSummary{
+			ID:        ratingPeriod.ID,
+			StartDate: ratingPeriod.StartDate,
+			EndDate:   ratingPeriod.EndDate,
+			Matches:   len(ratingPeriod.Matches),
+		}
+		for _, competitor := range ratingPeriod.Competitors {
+			if competitor.PostRating != nil {
+				summary.Teams++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// TeamSummary is the struct that identifies a Team.
+type TeamSummary struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (s *Server) handleTeams(w http.ResponseWriter, r *http.Request) {
+	d := s.snapshot()
+	teams := []*TeamSummary{}
+	for id, team := range d.teams {
+		teams = append(teams, &TeamSummary{ID: id, Name: team.Name})
+	}
+	sort.SliceStable(teams, func(i, j int) bool { return teams[i].ID < teams[j].ID })
+	writeJSON(w, http.StatusOK, teams)
+}
+
+func (s *Server) handleTeam(w http.ResponseWriter, r *http.Request) {
+	d := s.snapshot()
+	teamID, err := ranking.FindTeam(r.PathValue("team"), d.teams)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	// The periods have the Teams by the id of their lineage.
+	teamID = s.Resolver.Resolve(teamID, d.lastEndDate())
+	writeJSON(w, http.StatusOK, output.BuildTeamReport(teamID, d.history.Trajectory(teamID), d.teams))
+}
+
+// HeadToHead is the struct returned by the head-to-head endpoint, with the
+// Matches between two Teams and their current ratings.
+type HeadToHead struct {
+	Home      *Side              `json:"home"`
+	Away      *Side              `json:"away"`
+	Ties      int                `json:"ties"`
+	Matches   []*HeadToHeadMatch `json:"matches"`
+	HomeScore float64            `json:"home_expected_score"`
+}
+
+// Side is the struct that holds one of the Teams of a HeadToHead or a
+// prediction.
+type Side struct {
+	TeamID int     `json:"team_id"`
+	Team   string  `json:"team"`
+	Wins   int     `json:"wins"`
+	Rating float64 `json:"rating"`
+	RD     float64 `json:"rd"`
+}
+
+// HeadToHeadMatch is the struct that holds a Match of a HeadToHead.
+type HeadToHeadMatch struct {
+	MatchID  int `json:"match_id"`
+	Period   int `json:"period"`
+	WinnerID int `json:"winner_id"` // -1 for a tie.
+}
+
+func (s *Server) handleHeadToHead(w http.ResponseWriter, r *http.Request) {
+	d := s.snapshot()
+	home, away, ok := d.pair(w, r)
+	if !ok {
+		return
+	}
+
+	at := d.lastEndDate()
+	homeRating, awayRating := s.ratingAt(d, home, at), s.ratingAt(d, away, at)
+	result := &HeadToHead{
+		Home:      d.side(home, homeRating),
+		Away:      d.side(away, awayRating),
+		Matches:   []*HeadToHeadMatch{},
+		HomeScore: glicko.ExpectedScore(homeRating, awayRating),
+	}
+
+	// The periods have the Teams by the id of their lineage.
+	homeID, awayID := s.Resolver.Resolve(home, at), s.Resolver.Resolve(away, at)
+	for _, ratingPeriod := range d.periods {
+		for _, match := range ratingPeriod.Matches {
+			if !(match.Home.ID == homeID && match.Away.ID == awayID) && !(match.Home.ID == awayID && match.Away.ID == homeID) {
+				continue
+			}
+
+			switch match.Winner {
+			case homeID:
+				result.Home.Wins++
+			case awayID:
+				result.Away.Wins++
+			default:
+				result.Ties++
+			}
+			result.Matches = append(result.Matches, &HeadToHeadMatch{
+				MatchID:  match.ID,
+				Period:   ratingPeriod.ID,
+				WinnerID: match.Winner,
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// MatchPrediction is the struct returned by the predict endpoint.
+type MatchPrediction struct {
+	At         time.Time              `json:"at"`
+	Home       *Side                  `json:"home"`
+	Away       *Side                  `json:"away"`
+	Prediction *prediction.Prediction `json:"prediction"`
+}
+
+func (s *Server) handlePredict(w http.ResponseWriter, r *http.Request) {
+	d := s.snapshot()
+	query := &queryParser{values: r.URL.Query()}
+	at := query.moment("at", d.lastEndDate())
+	bestOf := query.integer("best_of", 3)
+	if query.err != nil {
+		writeError(w, http.StatusBadRequest, query.err)
+		return
+	}
+
+	home, away, ok := d.pair(w, r)
+	if !ok {
+		return
+	}
+
+	homeRating, awayRating := s.ratingAt(d, home, at), s.ratingAt(d, away, at)
+	predicted, err := prediction.Predict(homeRating, awayRating, bestOf)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &MatchPrediction{
+		At:         at,
+		Home:       d.side(home, homeRating),
+		Away:       d.side(away, awayRating),
+		Prediction: predicted,
+	})
+}
+
+// ratingAt returns the rating of the Team at the moment, or the default
+// rating if it didn't play until then.
+func (s *Server) ratingAt(d *data, teamID int, at time.Time) *glicko.Rating {
+	if snapshot := d.history.RatingAt(s.Resolver.Resolve(teamID, at), at); snapshot != nil {
+		return snapshot.Rating
+	}
+	return s.DefaultRating
+}
+
+// pair finds the Teams of the home and away query parameters, writing the
+// error when any is missing.
+func (d *data) pair(w http.ResponseWriter, r *http.Request) (home, away int, ok bool) {
+	query := r.URL.Query()
+	if query.Get("home") == "" || query.Get("away") == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("home and away are required"))
+		return 0, 0, false
+	}
+
+	var err error
+	if home, err = ranking.FindTeam(query.Get("home"), d.teams); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return 0, 0, false
+	}
+	if away, err = ranking.FindTeam(query.Get("away"), d.teams); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return 0, 0, false
+	}
+	return home, away, true
+}
+
+func (d *data) side(teamID int, rating *glicko.Rating) *Side {
+	side := &Side{TeamID: teamID, Team: fmt.Sprintf("#%d", teamID), Rating: rating.Rating, RD: rating.RatingDerivation}
+	if team, ok := d.teams[teamID]; ok {
+		side.Team = team.Name
+	}
+	return side
+}
+
+// lastEndDate is the end of the last period, the default moment of the
+// queries.
+func (d *data) lastEndDate() time.Time {
+	if len(d.periods) == 0 {
+		return time.Now().UTC()
+	}
+	return d.periods[len(d.periods)-1].EndDate
+}
+
+// queryParser parses the query parameters, keeping the first error.
+type queryParser struct {
+	values map[string][]string
+	err    error
+}
+
+func (q *queryParser) get(name string) string {
+	if values := q.values[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (q *queryParser) fail(name, value, expected string) {
+	if q.err == nil {
+		q.err = fmt.Errorf("invalid %s %q: expected %s", name, value, expected)
+	}
+}
+
+func (q *queryParser) integer(name string, fallback int) int {
+	value := q.get(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		q.fail(name, value, "a non negative integer")
+		return fallback
+	}
+	return parsed
+}
+
+func (q *queryParser) float(name string, fallback float64) float64 {
+	value := q.get(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 {
+		q.fail(name, value, "a non negative number")
+		return fallback
+	}
+	return parsed
+}
+
+func (q *queryParser) duration(name string, fallback time.Duration) time.Duration {
+	value := q.get(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := output.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		q.fail(name, value, "a duration like 30d or 720h")
+		return fallback
+	}
+	return parsed
+}
+
+func (q *queryParser) sortKey(name string, fallback output.SortKey) output.SortKey {
+	value := q.get(name)
+	if value == "" {
+		return fallback
+	}
+	key, err := output.ParseSortKey(value)
+	if err != nil {
+		q.fail(name, value, "rating, conservative or delta")
+		return fallback
+	}
+	return key
+}
+
+// moment parses RFC3339 or YYYY-MM-DD, for the end of that day.
+func (q *queryParser) moment(name string, fallback time.Time) time.Time {
+	value := q.get(name)
+	if value == "" {
+		return fallback
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.UTC()
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		q.fail(name, value, "RFC3339 or YYYY-MM-DD")
+		return fallback
+	}
+	return parsed.AddDate(0, 0, 1).Add(-time.Second)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/lineage"
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(day int) time.Time {
+	return time.Date(2019, 3, day, 0, 0, 0, 0, time.UTC)
+}
+
+func mockPeriods() []*glicko.RatingPeriod {
+	competitors := map[int]*glicko.RankableCompetitor{}
+	for id := 1; id <= 3; id++ {
+		competitors[id] = glicko.BuildRankableCompetitor(id, glicko.BuildDefaultRating())
+	}
+
+	first := glicko.BuildRatingPeriodWithTime(1, day(1), day(8))
+	first.AddNewMatch(competitors[1], competitors[2], 1)
+	first.AddNewMatch(competitors[1], competitors[3], 1)
+	first.Calculate()
+
+	second := glicko.BuildRatingPeriodWithTime(2, day(8).Add(time.Second), day(15))
+	second.AddNewMatch(
+		glicko.BuildRankableCompetitor(1, competitors[1].PostRating),
+		glicko.BuildRankableCompetitor(2, competitors[2].PostRating),
+		2,
+	)
+	second.Calculate()
+
+	return []*glicko.RatingPeriod{first, second}
+}
+
+func mockServer(t *testing.T) (*Server, *httptest.Server) {
+	periods := mockPeriods()
+	server := BuildServer(func() ([]*glicko.RatingPeriod, map[int]*ranking.Team, error) {
+		return periods, map[int]*ranking.Team{
+			1: {ID: 1, Name: "Astralis"},
+			2: {ID: 2, Name: "Liquid"},
+			3: {ID: 3, Name: "Natus Vincere"},
+		}, nil
+	})
+	assert.Nil(t, server.Reload())
+
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(httpServer.Close)
+	return server, httpServer
+}
+
+func get(t *testing.T, url string, value interface{}) int {
+	resp, err := http.Get(url)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(value))
+	return resp.StatusCode
+}
+
+func TestLeaderboard(t *testing.T) {
+	_, httpServer := mockServer(t)
+
+	page := &LeaderboardPage{}
+	assert.Equal(t, http.StatusOK, get(t, httpServer.URL+"/api/leaderboard", page))
+	assert.Equal(t, day(15), page.At)
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, 3, len(page.Rows))
+	assert.Equal(t, 1, page.Rows[0].Rank)
+
+	page = &LeaderboardPage{}
+	get(t, httpServer.URL+"/api/leaderboard?offset=1&limit=1", page)
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, 1, len(page.Rows))
+	assert.Equal(t, 2, page.Rows[0].Rank)
+
+	// Only 1 and 2 played the second period.
+	page = &LeaderboardPage{}
+	get(t, httpServer.URL+"/api/leaderboard?active_within=1d", page)
+	assert.Equal(t, 3, page.Total)
+	assert.True(t, page.Rows[2].Provisional)
+	assert.Equal(t, 3, page.Rows[2].TeamID)
+
+	page = &LeaderboardPage{}
+	get(t, httpServer.URL+"/api/leaderboard?at=2019-03-08", page)
+	assert.Equal(t, 1, page.Rows[0].TeamID)
+	assert.Equal(t, 1, page.Rows[0].Period)
+
+	failure := map[string]string{}
+	assert.Equal(t, http.StatusBadRequest, get(t, httpServer.URL+"/api/leaderboard?limit=0", &failure))
+	assert.Contains(t, failure["error"], "limit")
+	assert.Equal(t, http.StatusBadRequest, get(t, httpServer.URL+"/api/leaderboard?sort=volatility", &failure))
+}
+
+func TestPeriodsAndTeams(t *testing.T) {
+	_, httpServer := mockServer(t)
+
+	periods := []*PeriodSummary{}
+	assert.Equal(t, http.StatusOK, get(t, httpServer.URL+"/api/periods", &periods))
+	assert.Equal(t, 2, len(periods))
+	assert.Equal(t, 2, periods[0].Matches)
+	assert.Equal(t, 3, periods[0].Teams)
+
+	teams := []*TeamSummary{}
+	get(t, httpServer.URL+"/api/teams", &teams)
+	assert.Equal(t, 3, len(teams))
+
+	report := &output.TeamReport{}
+	assert.Equal(t, http.StatusOK, get(t, httpServer.URL+"/api/teams/natus%20vincere", report))
+	assert.Equal(t, 3, report.TeamID)
+	assert.Equal(t, 2, len(report.Points))
+	assert.False(t, report.Points[1].Played)
+
+	failure := map[string]string{}
+	assert.Equal(t, http.StatusNotFound, get(t, httpServer.URL+"/api/teams/42", &failure))
+}
+
+func TestHeadToHeadAndPredict(t *testing.T) {
+	_, httpServer := mockServer(t)
+
+	h2h := &HeadToHead{}
+	assert.Equal(t, http.StatusOK, get(t, httpServer.URL+"/api/head-to-head?home=1&away=Liquid", h2h))
+	assert.Equal(t, 2, len(h2h.Matches))
+	assert.Equal(t, 1, h2h.Home.Wins)
+	assert.Equal(t, 1, h2h.Away.Wins)
+	assert.Equal(t, 0, h2h.Ties)
+
+	predicted := &MatchPrediction{}
+	assert.Equal(t, http.StatusOK, get(t, httpServer.URL+"/api/predict?home=1&away=3&best_of=5", predicted))
+	assert.Equal(t, 5, predicted.Prediction.BestOf)
+	assert.Equal(t, "Natus Vincere", predicted.Away.Team)
+	assert.InDelta(t, 1, predicted.Prediction.HomeWinProbability+predicted.Prediction.AwayWinProbability, 0.000001)
+
+	failure := map[string]string{}
+	assert.Equal(t, http.StatusBadRequest, get(t, httpServer.URL+"/api/predict?home=1&away=3&best_of=2", &failure))
+	assert.Equal(t, http.StatusBadRequest, get(t, httpServer.URL+"/api/predict?home=1", &failure))
+}
+
+func TestRebrandedTeam(t *testing.T) {
+	periods := mockPeriods()
+	server := BuildServer(func() ([]*glicko.RatingPeriod, map[int]*ranking.Team, error) {
+		return periods, map[int]*ranking.Team{
+			1: {ID: 1, Name: "Astralis"},
+			2: {ID: 2, Name: "Liquid"},
+			3: {ID: 3, Name: "Natus Vincere"},
+			4: {ID: 4, Name: "Astralis Academy"},
+		}, nil
+	})
+	server.Resolver = lineage.BuildResolver([]*lineage.Alias{{FromID: 1, ToID: 4, EffectiveDate: day(1)}})
+	assert.Nil(t, server.Reload())
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	// The new id finds the periods of the lineage.
+	report := &output.TeamReport{}
+	assert.Equal(t, http.StatusOK, get(t, httpServer.URL+"/api/teams/4", report))
+	assert.Equal(t, 1, report.TeamID)
+	assert.Equal(t, 2, len(report.Points))
+
+	h2h := &HeadToHead{}
+	assert.Equal(t, http.StatusOK, get(t, httpServer.URL+"/api/head-to-head?home=4&away=2", h2h))
+	assert.Equal(t, 2, len(h2h.Matches))
+	assert.Equal(t, 1, h2h.Home.Wins)
+	assert.Equal(t, 1, h2h.Away.Wins)
+}
+
+func TestReload(t *testing.T) {
+	periods := mockPeriods()
+	calls := 0
+	server := BuildServer(func() ([]*glicko.RatingPeriod, map[int]*ranking.Team, error) {
+		calls++
+		if calls == 3 {
+			return nil, nil, fmt.Errorf("database is locked")
+		}
+		return periods[:calls], map[int]*ranking.Team{}, nil
+	})
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	// Nothing loaded yet.
+	status := &Status{}
+	get(t, httpServer.URL+"/api/status", status)
+	assert.Equal(t, 0, status.Periods)
+
+	assert.Nil(t, server.Reload())
+	assert.Equal(t, 1, server.Status().Periods)
+
+	assert.Nil(t, server.Reload())
+	get(t, httpServer.URL+"/api/status", status)
+	assert.Equal(t, 2, status.Periods)
+
+	// A failed reload keeps the previous data.
+	assert.NotNil(t, server.Reload())
+	assert.Equal(t, 2, server.Status().Periods)
+
+	// Read-only: other methods aren't allowed, and there is no way to
+	// trigger a reload.
+	resp, err := http.Post(httpServer.URL+"/api/reload", "application/json", strings.NewReader(""))
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Post(httpServer.URL+"/api/leaderboard", "application/json", strings.NewReader(""))
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
@@ -0,0 +1,128 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// Snapshot is the struct that holds the state of a Competitor at a specific
+// moment.
+type Snapshot struct {
+	CompetitorID    int
+	At              time.Time
+	Rating          *glicko.Rating // With the RD inflated for the inactive periods.
+	Rank            int
+	LastPeriodID    int // Last period the Competitor played, as of At.
+	InactivePeriods int // Periods closed since LastPeriodID.
+}
+
+// History is the struct that answers point-in-time questions about a list of
+// calculated RatingPeriods.
+type History struct {
+	periods []*glicko.RatingPeriod
+}
+
+// BuildHistory builds a History from calculated RatingPeriods.
+func BuildHistory(periods []*glicko.RatingPeriod) *History {
+	sorted := make([]*glicko.RatingPeriod, len(periods))
+	copy(sorted, periods)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].EndDate.Before(sorted[j].EndDate)
+	})
+
+	return &History{periods: sorted}
+}
+
+// Periods returns the RatingPeriods of the History, oldest first.
+func (h *History) Periods() []*glicko.RatingPeriod {
+	return h.periods
+}
+
+// RatingAt returns the Snapshot of the Competitor at the given moment, or nil
+// if it didn't play any period closed until then.
+//
+// Only the periods that ended until the moment are considered, since the
+// results of a period are only known when it is over. For each period closed
+// after the last one the Competitor played, the RD is inflated as the Glicko2
+// specification does for inactive players.
+func (h *History) RatingAt(competitorID int, at time.Time) *Snapshot {
+	for _, snapshot := range h.RankingAt(at) {
+		if snapshot.CompetitorID == competitorID {
+			return snapshot
+		}
+	}
+	return nil
+}
+
+// RankingAt returns the Snapshot of every Competitor that played until the
+// given moment, ordered by rank.
+func (h *History) RankingAt(at time.Time) []*Snapshot {
+	state := buildRankingState()
+	for _, ratingPeriod := range h.periods {
+		if ratingPeriod.EndDate.After(at) {
+			break
+		}
+		state.close(ratingPeriod)
+	}
+
+	return state.ranking(at)
+}
+
+// rankingState is the struct that keeps the last period played by each
+// Competitor while the periods are closed, oldest first, so the ranking after
+// each of them doesn't need to go over the previous ones again.
+type rankingState struct {
+	latest        map[int]*Snapshot // InactivePeriods is the amount of periods closed until LastPeriodID.
+	closedPeriods int
+}
+
+func buildRankingState() *rankingState {
+	return &rankingState{latest: map[int]*Snapshot{}}
+}
+
+// close registers the results of the next RatingPeriod.
+func (s *rankingState) close(ratingPeriod *glicko.RatingPeriod) {
+	s.closedPeriods++
+
+	for _, competitor := range ratingPeriod.Competitors {
+		if competitor.PostRating == nil {
+			continue
+		}
+		s.latest[competitor.ID] = &Snapshot{
+			CompetitorID:    competitor.ID,
+			Rating:          competitor.PostRating,
+			LastPeriodID:    ratingPeriod.ID,
+			InactivePeriods: s.closedPeriods,
+		}
+	}
+}
+
+// ranking builds the Snapshots of every Competitor with the periods closed so
+// far, ordered by rank.
+func (s *rankingState) ranking(at time.Time) []*Snapshot {
+	ranking := []*Snapshot{}
+	for _, latest := range s.latest {
+		inactivePeriods := s.closedPeriods - latest.InactivePeriods
+		ranking = append(ranking, &Snapshot{
+			CompetitorID:    latest.CompetitorID,
+			At:              at,
+			Rating:          latest.Rating.Inflate(inactivePeriods),
+			LastPeriodID:    latest.LastPeriodID,
+			InactivePeriods: inactivePeriods,
+		})
+	}
+
+	sort.SliceStable(ranking, func(i, j int) bool {
+		if ranking[i].Rating.Rating != ranking[j].Rating.Rating {
+			return ranking[i].Rating.Rating > ranking[j].Rating.Rating
+		}
+		return ranking[i].CompetitorID < ranking[j].CompetitorID
+	})
+	for i, snapshot := range ranking {
+		snapshot.Rank = i + 1
+	}
+
+	return ranking
+}
@@ -0,0 +1,143 @@
+package history
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(day int) time.Time {
+	return time.Date(2019, 3, day, 0, 0, 0, 0, time.UTC)
+}
+
+// mockPeriods builds two weekly periods: on the first 1 beats 2 and 3 beats
+// 4, on the second only 1 and 3 play (3 wins).
+func mockPeriods() []*glicko.RatingPeriod {
+	first := glicko.BuildRatingPeriodWithTime(1, day(1), day(8))
+	competitors := map[int]*glicko.RankableCompetitor{}
+	for id := 1; id <= 4; id++ {
+		competitors[id] = glicko.BuildRankableCompetitor(id, glicko.BuildDefaultRating())
+	}
+	first.AddNewMatch(competitors[1], competitors[2], 1)
+	first.AddNewMatch(competitors[3], competitors[4], 3)
+	first.Calculate()
+
+	second := glicko.BuildRatingPeriodWithTime(2, day(8).Add(time.Second), day(15))
+	second.AddNewMatch(
+		glicko.BuildRankableCompetitor(1, competitors[1].PostRating),
+		glicko.BuildRankableCompetitor(3, competitors[3].PostRating),
+		3,
+	)
+	second.Calculate()
+
+	return []*glicko.RatingPeriod{second, first}
+}
+
+func TestRatingAt(t *testing.T) {
+	periods := mockPeriods()
+	history := BuildHistory(periods)
+
+	// Nothing is known before the first period closes.
+	assert.Nil(t, history.RatingAt(1, day(5)))
+
+	snapshot := history.RatingAt(1, day(10))
+	assert.Equal(t, 1, snapshot.LastPeriodID)
+	assert.Equal(t, 0, snapshot.InactivePeriods)
+	assert.Equal(t, periods[1].Competitors[0].PostRating.Rating, snapshot.Rating.Rating)
+
+	snapshot = history.RatingAt(3, day(20))
+	assert.Equal(t, 2, snapshot.LastPeriodID)
+	assert.Equal(t, 1, snapshot.Rank)
+}
+
+func TestRatingAtInflatesInactive(t *testing.T) {
+	periods := mockPeriods()
+	history := BuildHistory(periods)
+
+	// 2 didn't play the second period, so its RD grows once.
+	original := periods[1].Competitors[1].PostRating
+	snapshot := history.RatingAt(2, day(20))
+
+	assert.Equal(t, 1, snapshot.LastPeriodID)
+	assert.Equal(t, 1, snapshot.InactivePeriods)
+	assert.Equal(t, original.Rating, snapshot.Rating.Rating)
+	assert.Equal(t, original.Volatility, snapshot.Rating.Volatility)
+
+	expected := 173.7178 * math.Sqrt(math.Pow(original.G2RatingDerivation, 2)+math.Pow(original.Volatility, 2))
+	assert.LessOrEqual(t, math.Abs(expected-snapshot.Rating.RatingDerivation), 0.0001)
+	assert.Greater(t, snapshot.Rating.RatingDerivation, original.RatingDerivation)
+}
+
+func TestRankingAt(t *testing.T) {
+	history := BuildHistory(mockPeriods())
+
+	ranking := history.RankingAt(day(10))
+
+	assert.Equal(t, 4, len(ranking))
+	for i, snapshot := range ranking {
+		assert.Equal(t, i+1, snapshot.Rank)
+		if i > 0 {
+			assert.GreaterOrEqual(t, ranking[i-1].Rating.Rating, snapshot.Rating.Rating)
+		}
+	}
+}
+
+func TestTrajectory(t *testing.T) {
+	history := BuildHistory(mockPeriods())
+
+	points := history.Trajectory(1)
+	assert.Equal(t, 2, len(points))
+	assert.True(t, points[1].Played)
+	assert.Equal(t, 1, len(points[0].Matches))
+	assert.Equal(t, 2, points[0].Matches[0].OpponentID)
+	assert.Equal(t, 1.0, points[0].Matches[0].Score)
+	assert.Equal(t, 3, points[1].Matches[0].OpponentID)
+	assert.Equal(t, 0.0, points[1].Matches[0].Score)
+	assert.Equal(t, points[0].PostRating.Rating, points[1].PreRating.Rating)
+
+	// 2 only played the first period, the second is kept with the RD inflated.
+	points = history.Trajectory(2)
+	assert.Equal(t, 2, len(points))
+	assert.False(t, points[1].Played)
+	assert.Nil(t, points[1].PreRating)
+	assert.Equal(t, points[0].PostRating.Rating, points[1].PostRating.Rating)
+	assert.Greater(t, points[1].PostRating.RatingDerivation, points[0].PostRating.RatingDerivation)
+
+	assert.Equal(t, 0, len(history.Trajectory(5)))
+}
+
+func TestTrajectoriesMatchRankingAt(t *testing.T) {
+	periods := mockPeriods()
+	// A third period without the Competitor 3, and a fourth ending at the same
+	// moment as it.
+	third := glicko.BuildRatingPeriodWithTime(3, day(15).Add(time.Second), day(22))
+	third.AddNewMatch(
+		glicko.BuildRankableCompetitor(2, periods[1].Competitor(2).PostRating),
+		glicko.BuildRankableCompetitor(4, periods[1].Competitor(4).PostRating),
+		4,
+	)
+	third.Calculate()
+	fourth := glicko.BuildRatingPeriodWithTime(4, day(15).Add(time.Second), day(22))
+	fourth.AddNewMatch(
+		glicko.BuildRankableCompetitor(5, glicko.BuildDefaultRating()),
+		glicko.BuildRankableCompetitor(1, periods[0].Competitor(1).PostRating),
+		5,
+	)
+	fourth.Calculate()
+	history := BuildHistory(append(periods, third, fourth))
+
+	trajectories := history.Trajectories()
+	assert.Equal(t, 5, len(trajectories))
+	for competitorID, points := range trajectories {
+		for _, point := range points {
+			snapshot := history.RatingAt(competitorID, point.EndDate)
+			assert.Equal(t, snapshot.Rating, point.PostRating)
+			assert.Equal(t, snapshot.Rank, point.Rank)
+		}
+	}
+	assert.Equal(t, 4, len(trajectories[3]))
+	assert.Equal(t, 1, len(trajectories[5]))
+}
@@ -0,0 +1,115 @@
+package history
+
+import (
+	"time"
+
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// Point is the struct that holds the state of a Competitor by the end of a
+// period of its Trajectory.
+type Point struct {
+	PeriodID   int
+	StartDate  time.Time
+	EndDate    time.Time
+	Played     bool           // False when the Competitor had no Matches on the period.
+	PreRating  *glicko.Rating // Nil when the Competitor didn't play.
+	PostRating *glicko.Rating // With the RD inflated when the Competitor didn't play.
+	Rank       int
+	Matches    []*MatchResult
+}
+
+// MatchResult is the struct that holds a Match of a Competitor from its point
+// of view.
+type MatchResult struct {
+	MatchID        int
+	OpponentID     int
+	OpponentRating *glicko.Rating // Rating of the opponent before the period.
+	Score          float64        // 1 for a win, 0.5 for a tie and 0 for a loss.
+}
+
+// Trajectory returns a Point for each period closed since the first one the
+// Competitor played, the periods without Matches included.
+func (h *History) Trajectory(competitorID int) []*Point {
+	return h.Trajectories()[competitorID]
+}
+
+// Trajectories returns the Trajectory of every Competitor, keyed by its id.
+// The rankings at the end of the periods are built in a single pass over
+// them, so it is cheaper than calling Trajectory for each Competitor.
+func (h *History) Trajectories() map[int][]*Point {
+	trajectories := map[int][]*Point{}
+	state := buildRankingState()
+	closed := 0
+	for _, ratingPeriod := range h.periods {
+		// Same as RankingAt(ratingPeriod.EndDate): the periods that end at
+		// the same moment are closed together.
+		for closed < len(h.periods) && !h.periods[closed].EndDate.After(ratingPeriod.EndDate) {
+			state.close(h.periods[closed])
+			closed++
+		}
+
+		played := map[int]*glicko.RankableCompetitor{}
+		for _, competitor := range ratingPeriod.Competitors {
+			if competitor.PostRating != nil {
+				played[competitor.ID] = competitor
+				if _, ok := trajectories[competitor.ID]; !ok {
+					trajectories[competitor.ID] = []*Point{}
+				}
+			}
+		}
+
+		snapshots := map[int]*Snapshot{}
+		for _, snapshot := range state.ranking(ratingPeriod.EndDate) {
+			snapshots[snapshot.CompetitorID] = snapshot
+		}
+
+		for competitorID, points := range trajectories {
+			competitor, ok := played[competitorID]
+			point := &Point{
+				PeriodID:  ratingPeriod.ID,
+				StartDate: ratingPeriod.StartDate,
+				EndDate:   ratingPeriod.EndDate,
+				Played:    ok,
+			}
+			if snapshot, ok := snapshots[competitorID]; ok {
+				point.PostRating = snapshot.Rating
+				point.Rank = snapshot.Rank
+			}
+			if competitor != nil {
+				point.PreRating = competitor.PreRating
+				point.Matches = matchResults(competitor)
+			}
+
+			trajectories[competitorID] = append(points, point)
+		}
+	}
+
+	return trajectories
+}
+
+func matchResults(competitor *glicko.RankableCompetitor) []*MatchResult {
+	results := []*MatchResult{}
+	for _, match := range competitor.Matches {
+		opponent := match.Away
+		if match.Away.ID == competitor.ID {
+			opponent = match.Home
+		}
+
+		score := 0.0
+		switch match.Winner {
+		case competitor.ID:
+			score = 1
+		case -1:
+			score = 0.5
+		}
+
+		results = append(results, &MatchResult{
+			MatchID:        match.ID,
+			OpponentID:     opponent.ID,
+			OpponentRating: opponent.PreRating,
+			Score:          score,
+		})
+	}
+	return results
+}
@@ -0,0 +1,63 @@
+package lineage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// Alias is the struct that represents one entry of the lineage file. It
+// declares that, from the EffectiveDate onwards, the Team with ToID is the
+// continuation of the Team with FromID (rebrand, org buying a roster, etc).
+type Alias struct {
+	FromID        int       `json:"from"`
+	ToID          int       `json:"to"`
+	EffectiveDate time.Time `json:"-"`
+	Note          string    `json:"note,omitempty"`
+
+	RawEffectiveDate string `json:"effective_date"`
+}
+
+// dateLayouts are the layouts accepted for the `effective_date` field.
+var dateLayouts = []string{time.RFC3339, "2006-01-02"}
+
+// LoadFile reads a JSON lineage file, which consists of a list of Aliases.
+//
+// Example:
+//
+//	[{"from": 123, "to": 456, "effective_date": "2019-01-15", "note": "Rebrand"}]
+func LoadFile(path string) ([]*Alias, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var aliases []*Alias
+	if err := json.Unmarshal(content, &aliases); err != nil {
+		return nil, fmt.Errorf("lineage: invalid file %s: %v", path, err)
+	}
+
+	for i, alias := range aliases {
+		if err := alias.parseEffectiveDate(); err != nil {
+			return nil, fmt.Errorf("lineage: entry %d: %v", i, err)
+		}
+		if alias.FromID == alias.ToID {
+			return nil, fmt.Errorf("lineage: entry %d: team %d aliased to itself", i, alias.FromID)
+		}
+	}
+
+	return aliases, nil
+}
+
+// parseEffectiveDate fills the EffectiveDate based on the raw value read from
+// the file.
+func (a *Alias) parseEffectiveDate() error {
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, a.RawEffectiveDate); err == nil {
+			a.EffectiveDate = parsed.UTC()
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid effective_date %q", a.RawEffectiveDate)
+}
@@ -0,0 +1,67 @@
+package lineage
+
+import (
+	"sort"
+	"time"
+)
+
+// Resolver is the struct that translates Team ids into the id of the lineage
+// they belong to, so the ratings can be carried across rebrands.
+type Resolver struct {
+	aliasesByTarget map[int][]*Alias
+}
+
+// BuildResolver builds a Resolver based on a list of Aliases.
+func BuildResolver(aliases []*Alias) *Resolver {
+	resolver := &Resolver{aliasesByTarget: map[int][]*Alias{}}
+
+	for _, alias := range aliases {
+		resolver.aliasesByTarget[alias.ToID] = append(resolver.aliasesByTarget[alias.ToID], alias)
+	}
+
+	// Latest first, so the lookup can stop on the first effective Alias.
+	for _, targetAliases := range resolver.aliasesByTarget {
+		sort.SliceStable(targetAliases, func(i, j int) bool {
+			return targetAliases[i].EffectiveDate.After(targetAliases[j].EffectiveDate)
+		})
+	}
+
+	return resolver
+}
+
+// Resolve returns the id under which the Team with the given id should be
+// rated at a specific moment. When no Alias applies the id itself is
+// returned. Chains are followed (A -> B -> C resolves C to A), and matches
+// that a Team played before its Alias became effective are kept on its own
+// id, so an org that picks up a new roster doesn't inherit it backwards.
+func (r *Resolver) Resolve(id int, at time.Time) int {
+	if r == nil {
+		return id
+	}
+
+	visited := map[int]bool{}
+	for !visited[id] {
+		visited[id] = true
+
+		alias := r.effectiveAlias(id, at)
+		if alias == nil {
+			return id
+		}
+
+		id = alias.FromID
+		at = alias.EffectiveDate
+	}
+
+	return id
+}
+
+// effectiveAlias finds the most recent Alias that targets the id and is
+// already effective at the given moment.
+func (r *Resolver) effectiveAlias(id int, at time.Time) *Alias {
+	for _, alias := range r.aliasesByTarget[id] {
+		if !alias.EffectiveDate.After(at) {
+			return alias
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package lineage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestLoadFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "lineage")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "aliases.json")
+	ioutil.WriteFile(path, []byte(`[
+		{"from": 1, "to": 2, "effective_date": "2019-01-15"},
+		{"from": 2, "to": 3, "effective_date": "2019-02-01T12:00:00Z", "note": "Org move"}
+	]`), 0644)
+
+	aliases, err := LoadFile(path)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(aliases))
+	assert.Equal(t, date(2019, 1, 15), aliases[0].EffectiveDate)
+	assert.Equal(t, "Org move", aliases[1].Note)
+}
+
+func TestLoadFileInvalidDate(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "lineage")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "aliases.json")
+	ioutil.WriteFile(path, []byte(`[{"from": 1, "to": 2, "effective_date": "15/01/2019"}]`), 0644)
+
+	_, err := LoadFile(path)
+
+	assert.NotNil(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	resolver := BuildResolver([]*Alias{
+		{FromID: 1, ToID: 2, EffectiveDate: date(2019, 1, 15)},
+		{FromID: 2, ToID: 3, EffectiveDate: date(2019, 2, 1)},
+	})
+
+	assert.Equal(t, 1, resolver.Resolve(1, date(2019, 3, 1)))
+	assert.Equal(t, 1, resolver.Resolve(2, date(2019, 1, 20)))
+	assert.Equal(t, 1, resolver.Resolve(3, date(2019, 2, 10)))
+	assert.Equal(t, 42, resolver.Resolve(42, date(2019, 2, 10)))
+
+	// Before the Alias is effective the Team keeps its own lineage.
+	assert.Equal(t, 2, resolver.Resolve(2, date(2019, 1, 1)))
+	assert.Equal(t, 3, resolver.Resolve(3, date(2019, 1, 20)))
+}
+
+func TestResolveCycle(t *testing.T) {
+	resolver := BuildResolver([]*Alias{
+		{FromID: 1, ToID: 2, EffectiveDate: date(2019, 1, 1)},
+		{FromID: 2, ToID: 1, EffectiveDate: date(2019, 1, 1)},
+	})
+
+	assert.NotPanics(t, func() { resolver.Resolve(1, date(2019, 2, 1)) })
+}
+
+func TestSuggest(t *testing.T) {
+	old := &TeamActivity{ID: 1, Name: "Team Liquid Academy", FirstSeen: date(2019, 1, 1), LastSeen: date(2019, 1, 31)}
+	renamed := &TeamActivity{ID: 2, Name: "Liquid", FirstSeen: date(2019, 2, 5), LastSeen: date(2019, 3, 1)}
+	closed := &TeamActivity{ID: 3, Name: "Alpha", FirstSeen: date(2019, 1, 1), LastSeen: date(2019, 1, 20)}
+	unrelated := &TeamActivity{ID: 4, Name: "Omega Esports", FirstSeen: date(2019, 2, 1), LastSeen: date(2019, 3, 1)}
+
+	suggestions := Suggest([]*TeamActivity{old, renamed, closed, unrelated}, nil)
+
+	assert.Equal(t, 1, len(suggestions))
+	assert.Equal(t, 1, suggestions[0].From.ID)
+	assert.Equal(t, 2, suggestions[0].To.ID)
+
+	// Already aliased pairs are not suggested again.
+	resolver := BuildResolver([]*Alias{{FromID: 1, ToID: 2, EffectiveDate: date(2019, 2, 1)}})
+	suggestions = Suggest([]*TeamActivity{old, renamed}, resolver)
+
+	assert.Equal(t, 0, len(suggestions))
+}
@@ -0,0 +1,118 @@
+package lineage
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TeamActivity is the struct that summarizes what is known about a Team
+// during the analysed range, used to look for likely merges.
+type TeamActivity struct {
+	ID        int
+	Name      string
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Suggestion is the struct that holds a possible Alias found by Suggest.
+type Suggestion struct {
+	From           *TeamActivity
+	To             *TeamActivity
+	NameSimilarity float64
+}
+
+// SuggestionThreshold is the minimum name similarity for a pair of Teams to be
+// suggested as the same lineage.
+var SuggestionThreshold = 0.6
+
+// Suggest looks for pairs of Teams where one stopped playing before the other
+// started and that have very similar names. Pairs already linked by the
+// Resolver are ignored. The result is sorted by similarity, best first.
+func Suggest(teams []*TeamActivity, resolver *Resolver) []*Suggestion {
+	suggestions := []*Suggestion{}
+
+	for _, from := range teams {
+		for _, to := range teams {
+			if from.ID == to.ID || !from.LastSeen.Before(to.FirstSeen) {
+				continue
+			}
+			if resolver.Resolve(to.ID, to.FirstSeen) == resolver.Resolve(from.ID, from.LastSeen) {
+				continue
+			}
+
+			similarity := nameSimilarity(from.Name, to.Name)
+			if similarity >= SuggestionThreshold {
+				suggestions = append(suggestions, &Suggestion{From: from, To: to, NameSimilarity: similarity})
+			}
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].NameSimilarity > suggestions[j].NameSimilarity
+	})
+
+	return suggestions
+}
+
+// noiseWords are words commonly added/removed on rebrands that don't help
+// to identify a Team.
+var noiseWords = map[string]bool{
+	"team": true, "esports": true, "esport": true, "gaming": true,
+	"club": true, "gg": true, "academy": true,
+}
+
+// normalizeName lowercases the name and strips punctuation and noise words.
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	kept := []string{}
+	for _, word := range words {
+		if !noiseWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// nameSimilarity is 1 minus the normalized Levenshtein distance between the
+// normalized names.
+func nameSimilarity(a, b string) float64 {
+	ra := []rune(normalizeName(a))
+	rb := []rune(normalizeName(b))
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
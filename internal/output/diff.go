@@ -0,0 +1,291 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Status of a Team on a Diff.
+const (
+	StatusRanked  = ""
+	StatusNew     = "new"     // Ranked on the newer ranking only.
+	StatusDropped = "dropped" // Ranked on the older ranking only.
+)
+
+// DiffRow is the struct that holds the change of a Team between two rankings.
+// The ranks are 0 where the Team isn't ranked (missing or provisional), and
+// the ratings are 0 where it hasn't been rated yet.
+type DiffRow struct {
+	TeamID     int     `json:"team_id"`
+	Team       string  `json:"team"`
+	Status     string  `json:"status,omitempty"`
+	FromRank   int     `json:"from_rank"`
+	ToRank     int     `json:"to_rank"`
+	RankChange int     `json:"rank_change"` // Positive when the Team climbed.
+	FromRating float64 `json:"from_rating"`
+	ToRating   float64 `json:"to_rating"`
+	Delta      float64 `json:"delta"` // 0 unless the Team was rated on both.
+}
+
+// Diff is the struct that holds the changes between two rankings.
+type Diff struct {
+	From    time.Time  `json:"from"`
+	To      time.Time  `json:"to"`
+	Rows    []*DiffRow `json:"rows"`    // Ranked Teams by the new rank, then the dropped ones.
+	Risers  []*DiffRow `json:"risers"`  // Biggest positive deltas.
+	Fallers []*DiffRow `json:"fallers"` // Biggest negative deltas.
+}
+
+var diffHeader = []string{"team_id", "team", "status", "from_rank", "to_rank", "rank_change", "from_rating",
+	"to_rating", "delta"}
+
+// Compare builds the Diff between two rankings (as returned by
+// Leaderboard.Apply), listing up to movers Teams on each side of the biggest
+// risers and fallers.
+func Compare(from, to []*Row, movers int) *Diff {
+	diff := &Diff{Rows: []*DiffRow{}, Risers: []*DiffRow{}, Fallers: []*DiffRow{}}
+	if len(from) > 0 {
+		diff.From = from[0].At
+	}
+	if len(to) > 0 {
+		diff.To = to[0].At
+	}
+
+	previous := map[int]*Row{}
+	for _, row := range from {
+		previous[row.TeamID] = row
+	}
+
+	rated := []*DiffRow{}
+	current := map[int]bool{}
+	for _, row := range to {
+		current[row.TeamID] = true
+
+		diffRow := &DiffRow{
+			TeamID:   row.TeamID,
+			Team:     row.Team,
+			ToRank:   row.Rank,
+			ToRating: row.Rating,
+		}
+		if old, ok := previous[row.TeamID]; ok {
+			diffRow.FromRank = old.Rank
+			diffRow.FromRating = old.Rating
+			diffRow.Delta = row.Rating - old.Rating
+		}
+
+		switch {
+		case diffRow.ToRank == 0 && diffRow.FromRank == 0:
+			continue // Not ranked on either.
+		case diffRow.ToRank == 0:
+			diffRow.Status = StatusDropped
+		case diffRow.FromRank == 0:
+			diffRow.Status = StatusNew
+		default:
+			diffRow.RankChange = diffRow.FromRank - diffRow.ToRank
+		}
+		diff.Rows = append(diff.Rows, diffRow)
+		if diffRow.FromRating != 0 {
+			rated = append(rated, diffRow)
+		}
+	}
+
+	// Teams that only show up on the older ranking.
+	for _, row := range from {
+		if current[row.TeamID] || row.Rank == 0 {
+			continue
+		}
+		diff.Rows = append(diff.Rows, &DiffRow{
+			TeamID:     row.TeamID,
+			Team:       row.Team,
+			Status:     StatusDropped,
+			FromRank:   row.Rank,
+			FromRating: row.Rating,
+		})
+	}
+
+	sort.SliceStable(diff.Rows, func(i, j int) bool {
+		a, b := diff.Rows[i], diff.Rows[j]
+		if (a.Status == StatusDropped) != (b.Status == StatusDropped) {
+			return b.Status == StatusDropped
+		}
+		if a.Status == StatusDropped {
+			return a.FromRank < b.FromRank
+		}
+		return a.ToRank < b.ToRank
+	})
+
+	sort.SliceStable(rated, func(i, j int) bool {
+		return rated[i].Delta > rated[j].Delta
+	})
+	for _, row := range rated {
+		if row.Delta > 0 && len(diff.Risers) < movers {
+			diff.Risers = append(diff.Risers, row)
+		}
+	}
+	for i := len(rated) - 1; i >= 0; i-- {
+		if rated[i].Delta < 0 && len(diff.Fallers) < movers {
+			diff.Fallers = append(diff.Fallers, rated[i])
+		}
+	}
+
+	return diff
+}
+
+// WithStatus returns the Rows of the Diff with the given status.
+func (d *Diff) WithStatus(status string) []*DiffRow {
+	rows := []*DiffRow{}
+	for _, row := range d.Rows {
+		if row.Status == status {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// WriteDiff writes the Diff on the given Format. CSV only has the Rows, the
+// movers can be derived from them.
+func WriteDiff(w io.Writer, format Format, diff *Diff) error {
+	switch format {
+	case FormatTable:
+		return writeDiffTable(w, diff)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	case FormatCSV:
+		return writeDiffCSV(w, diff)
+	case FormatMarkdown:
+		return writeDiffMarkdown(w, diff)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func writeDiffTable(w io.Writer, diff *Diff) error {
+	fmt.Fprintf(w, "%s -> %s\n\n", diff.From.Format("2006-01-02"), diff.To.Format("2006-01-02"))
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "RANK\tCHANGE\tID\tTEAM\tFROM\tTO\tDELTA\t")
+	for _, row := range diff.Rows {
+		fmt.Fprintf(table, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t\n", formatDiffRank(row.ToRank), formatRankChange(row),
+			row.TeamID, row.Team, formatDiffRating(row.FromRating), formatDiffRating(row.ToRating), formatDelta(row))
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for _, section := range []struct {
+		title string
+		rows  []*DiffRow
+	}{{"BIGGEST RISERS", diff.Risers}, {"BIGGEST FALLERS", diff.Fallers}} {
+		if len(section.rows) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\n", section.title)
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(table, "ID\tTEAM\tFROM\tTO\tDELTA\t")
+		for _, row := range section.rows {
+			fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\t\n", row.TeamID, row.Team, formatDiffRating(row.FromRating),
+				formatDiffRating(row.ToRating), formatDelta(row))
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeDiffCSV(w io.Writer, diff *Diff) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(diffHeader); err != nil {
+		return err
+	}
+	for _, row := range diff.Rows {
+		err := writer.Write([]string{
+			strconv.Itoa(row.TeamID),
+			row.Team,
+			row.Status,
+			strconv.Itoa(row.FromRank),
+			strconv.Itoa(row.ToRank),
+			strconv.Itoa(row.RankChange),
+			formatFloat(row.FromRating),
+			formatFloat(row.ToRating),
+			formatFloat(row.Delta),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeDiffMarkdown(w io.Writer, diff *Diff) error {
+	lines := []string{
+		fmt.Sprintf("**%s -> %s**", diff.From.Format("2006-01-02"), diff.To.Format("2006-01-02")),
+		"",
+		"| Rank | Change | Team | From | To | Delta |",
+		"| ---: | ---: | --- | ---: | ---: | ---: |",
+	}
+	for _, row := range diff.Rows {
+		lines = append(lines, fmt.Sprintf("| %s | %s | %s | %s | %s | %s |", formatDiffRank(row.ToRank),
+			formatRankChange(row), strings.ReplaceAll(row.Team, "|", `\|`), formatDiffRating(row.FromRating),
+			formatDiffRating(row.ToRating), formatDelta(row)))
+	}
+	for _, section := range []struct {
+		title string
+		rows  []*DiffRow
+	}{{"Biggest risers", diff.Risers}, {"Biggest fallers", diff.Fallers}} {
+		if len(section.rows) == 0 {
+			continue
+		}
+		lines = append(lines, "", fmt.Sprintf("**%s**", section.title), "")
+		for _, row := range section.rows {
+			lines = append(lines, fmt.Sprintf("- %s: %s", strings.ReplaceAll(row.Team, "|", `\|`), formatDelta(row)))
+		}
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func formatDiffRank(rank int) string {
+	if rank == 0 {
+		return "-"
+	}
+	return strconv.Itoa(rank)
+}
+
+func formatRankChange(row *DiffRow) string {
+	switch {
+	case row.Status == StatusNew:
+		return "NEW"
+	case row.Status == StatusDropped:
+		return "OUT"
+	case row.RankChange > 0:
+		return fmt.Sprintf("+%d", row.RankChange)
+	case row.RankChange < 0:
+		return strconv.Itoa(row.RankChange)
+	}
+	return "="
+}
+
+func formatDiffRating(rating float64) string {
+	if rating == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", rating)
+}
+
+func formatDelta(row *DiffRow) string {
+	if row.FromRating == 0 || row.ToRating == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.2f", row.Delta)
+}
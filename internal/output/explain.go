@@ -0,0 +1,138 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// ExplainedMatch is the serializable form of a glicko.MatchExplanation.
+type ExplainedMatch struct {
+	MatchID              int     `json:"match_id,omitempty"`
+	OpponentID           int     `json:"opponent_id"`
+	Opponent             string  `json:"opponent"`
+	OpponentRating       float64 `json:"opponent_rating"`
+	OpponentRD           float64 `json:"opponent_rd"`
+	G                    float64 `json:"g"`
+	E                    float64 `json:"e"`
+	Score                float64 `json:"score"`
+	DeltaContribution    float64 `json:"delta_contribution"`
+	G2RatingContribution float64 `json:"mu_contribution"`
+	RatingContribution   float64 `json:"rating_contribution"`
+}
+
+// ExplanationReport is the struct that holds the breakdown of the rating
+// change of a Team on a period.
+type ExplanationReport struct {
+	TeamID    int               `json:"team_id"`
+	Team      string            `json:"team"`
+	Period    int               `json:"period"`
+	StartDate time.Time         `json:"start_date"`
+	EndDate   time.Time         `json:"end_date"`
+	Tau       float64           `json:"tau"`
+	Matches   []*ExplainedMatch `json:"matches"`
+
+	PreRating     float64 `json:"pre_rating"`
+	PreRD         float64 `json:"pre_rd"`
+	PreVolatility float64 `json:"pre_volatility"`
+	Mu            float64 `json:"mu"`
+	Phi           float64 `json:"phi"`
+	V             float64 `json:"v"`
+	Delta         float64 `json:"delta"`
+	A             float64 `json:"a"`
+	Volatility    float64 `json:"volatility"`
+	PhiStar       float64 `json:"phi_star"`
+	PostPhi       float64 `json:"post_phi"`
+	PostMu        float64 `json:"post_mu"`
+	PostRating    float64 `json:"post_rating"`
+	PostRD        float64 `json:"post_rd"`
+}
+
+// BuildExplanationReport builds the ExplanationReport of a Team on the period.
+func BuildExplanationReport(ratingPeriod *glicko.RatingPeriod, explanation *glicko.Explanation, teams map[int]*ranking.Team) *ExplanationReport {
+	report := &ExplanationReport{
+		TeamID:        explanation.CompetitorID,
+		Team:          teamName(teams, explanation.CompetitorID),
+		Period:        ratingPeriod.ID,
+		StartDate:     ratingPeriod.StartDate,
+		EndDate:       ratingPeriod.EndDate,
+		Tau:           ratingPeriod.SystemConstant,
+		Matches:       []*ExplainedMatch{},
+		PreRating:     explanation.PreRating.Rating,
+		PreRD:         explanation.PreRating.RatingDerivation,
+		PreVolatility: explanation.PreRating.Volatility,
+		Mu:            explanation.PreRating.G2Rating,
+		Phi:           explanation.PreRating.G2RatingDerivation,
+		V:             explanation.V,
+		Delta:         explanation.Delta,
+		A:             explanation.A,
+		Volatility:    explanation.Volatility,
+		PhiStar:       explanation.PreRatingDerivation,
+		PostPhi:       explanation.PostRating.G2RatingDerivation,
+		PostMu:        explanation.PostRating.G2Rating,
+		PostRating:    explanation.PostRating.Rating,
+		PostRD:        explanation.PostRating.RatingDerivation,
+	}
+
+	for _, match := range explanation.Matches {
+		report.Matches = append(report.Matches, &ExplainedMatch{
+			MatchID:              match.Match.ID,
+			OpponentID:           match.Opponent.ID,
+			Opponent:             teamName(teams, match.Opponent.ID),
+			OpponentRating:       match.Opponent.PreRating.Rating,
+			OpponentRD:           match.Opponent.PreRating.RatingDerivation,
+			G:                    match.G,
+			E:                    match.E,
+			Score:                match.Result,
+			DeltaContribution:    match.DeltaContribution,
+			G2RatingContribution: match.G2RatingContribution,
+			RatingContribution:   match.RatingContribution,
+		})
+	}
+
+	return report
+}
+
+// WriteExplanationReport writes the ExplanationReport as JSON with FormatJSON
+// and as text otherwise.
+func WriteExplanationReport(w io.Writer, format Format, report *ExplanationReport) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Fprintf(w, "%s (#%d) on period %d (%s - %s)\n", report.Team, report.TeamID, report.Period,
+		report.StartDate.Format("2006-01-02"), report.EndDate.Format("2006-01-02"))
+	fmt.Fprintf(w, "%.2f ± %.2f -> %.2f ± %.2f (%+.2f)\n\n", report.PreRating, report.PreRD,
+		report.PostRating, report.PostRD, report.PostRating-report.PreRating)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "RESULT\tOPPONENT\tG(φj)\tE\tS-E\tΔ PART\tµ' PART\tRATING")
+	for _, match := range report.Matches {
+		fmt.Fprintf(table, "%s\t%s (%.0f ± %.0f)\t%.4f\t%.4f\t%+.4f\t%+.4f\t%+.4f\t%+.2f\n",
+			resultLetter(match.Score), match.Opponent, match.OpponentRating, match.OpponentRD,
+			match.G, match.E, match.Score-match.E, match.DeltaContribution, match.G2RatingContribution,
+			match.RatingContribution)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	steps := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(steps, "µ, φ, σ\t%.6f, %.6f, %.6f\tPre rating on the Glicko2 scale.\n", report.Mu, report.Phi, report.PreVolatility)
+	fmt.Fprintf(steps, "v\t%.6f\tEstimated variance from the Matches.\n", report.V)
+	fmt.Fprintf(steps, "Δ\t%+.6f\tEstimated improvement, sum of the Δ parts.\n", report.Delta)
+	fmt.Fprintf(steps, "a\t%.6f\tln(σ²), start of the volatility iteration (τ = %g).\n", report.A, report.Tau)
+	fmt.Fprintf(steps, "σ'\t%.6f\tNew volatility.\n", report.Volatility)
+	fmt.Fprintf(steps, "φ*\t%.6f\t√(φ² + σ'²), φ increased by the new volatility.\n", report.PhiStar)
+	fmt.Fprintf(steps, "φ'\t%.6f\t1 / √(1/φ*² + 1/v), RD' = %.2f.\n", report.PostPhi, report.PostRD)
+	fmt.Fprintf(steps, "µ'\t%+.6f\tµ + sum of the µ' parts, r' = %.2f.\n", report.PostMu, report.PostRating)
+	return steps.Flush()
+}
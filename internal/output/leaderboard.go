@@ -0,0 +1,121 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SortKey is the value by which the Rows of a Leaderboard are ranked.
+type SortKey string
+
+// Available sort keys.
+const (
+	SortRating       SortKey = "rating"
+	SortConservative SortKey = "conservative"
+	SortDelta        SortKey = "delta"
+)
+
+// ParseSortKey validates the name of a SortKey.
+func ParseSortKey(name string) (SortKey, error) {
+	for _, key := range []SortKey{SortRating, SortConservative, SortDelta} {
+		if string(key) == name {
+			return key, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sort key %q (available: rating, conservative, delta)", name)
+}
+
+// ParseDuration parses a time.Duration, accepting days (30d) besides the
+// units of time.ParseDuration.
+func ParseDuration(value string) (time.Duration, error) {
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		amount, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(amount) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// Leaderboard is the struct that holds the rules for a Team to be ranked.
+// The Teams that don't qualify are listed as provisional instead. The zero
+// value ranks every Team by rating.
+type Leaderboard struct {
+	Top          int           // Amount of ranked Teams on each period, 0 for all.
+	MinMatches   int           // Minimum amount of Matches played until the period.
+	MaxRD        float64       // Maximum RD, 0 for no limit.
+	ActiveWithin time.Duration // Maximum time since the last period played, 0 for no limit.
+	SortBy       SortKey
+}
+
+// Apply sorts and ranks the Rows of each period by the SortBy key, marking
+// the ones that don't qualify as provisional. The provisional Rows of a period
+// are placed after its ranked ones.
+func (l *Leaderboard) Apply(rows []*Row) []*Row {
+	periods := []int{}
+	byPeriod := map[int][]*Row{}
+	for _, row := range rows {
+		if _, ok := byPeriod[row.Period]; !ok {
+			periods = append(periods, row.Period)
+		}
+		byPeriod[row.Period] = append(byPeriod[row.Period], row)
+	}
+
+	result := []*Row{}
+	for _, period := range periods {
+		periodRows := byPeriod[period]
+		sort.SliceStable(periodRows, func(i, j int) bool {
+			a, b := l.sortValue(periodRows[i]), l.sortValue(periodRows[j])
+			if a != b {
+				return a > b
+			}
+			return periodRows[i].TeamID < periodRows[j].TeamID
+		})
+
+		ranked, provisional := []*Row{}, []*Row{}
+		for _, row := range periodRows {
+			row.Provisional = !l.qualifies(row)
+			if row.Provisional {
+				row.Rank = 0
+				provisional = append(provisional, row)
+				continue
+			}
+			row.Rank = len(ranked) + 1
+			ranked = append(ranked, row)
+		}
+		if l.Top > 0 && len(ranked) > l.Top {
+			ranked = ranked[:l.Top]
+		}
+
+		result = append(result, ranked...)
+		result = append(result, provisional...)
+	}
+
+	return result
+}
+
+func (l *Leaderboard) qualifies(row *Row) bool {
+	switch {
+	case row.TotalMatches < l.MinMatches:
+		return false
+	case l.MaxRD > 0 && row.RD > l.MaxRD:
+		return false
+	case l.ActiveWithin > 0 && row.At.Sub(row.LastActive) > l.ActiveWithin:
+		return false
+	}
+	return true
+}
+
+func (l *Leaderboard) sortValue(row *Row) float64 {
+	switch l.SortBy {
+	case SortConservative:
+		return row.Conservative
+	case SortDelta:
+		return row.Delta
+	}
+	return row.Rating
+}
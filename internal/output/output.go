@@ -0,0 +1,243 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// Format is the format in which the rankings are written.
+type Format string
+
+// Available formats.
+const (
+	FormatTable    Format = "table"
+	FormatJSON     Format = "json"
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "markdown"
+)
+
+// Formats contains the list of formats supported by Write.
+var Formats = []Format{FormatTable, FormatJSON, FormatCSV, FormatMarkdown}
+
+// ParseFormat validates the name of a Format.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q (available: table, json, csv, markdown)", name)
+}
+
+// Row is the struct that holds the state of a Team by the end of a period.
+type Row struct {
+	Period       int       `json:"period"`
+	Rank         int       `json:"rank"` // 0 for provisional Rows.
+	TeamID       int       `json:"team_id"`
+	Team         string    `json:"team"`
+	Rating       float64   `json:"rating"`
+	RD           float64   `json:"rd"`
+	Volatility   float64   `json:"volatility"`
+	Conservative float64   `json:"conservative"`
+	IntervalLow  float64   `json:"interval_low"` // 95% confidence interval.
+	IntervalHigh float64   `json:"interval_high"`
+	Delta        float64   `json:"delta"`
+	Matches      int       `json:"matches"`
+	TotalMatches int       `json:"total_matches"`
+	At           time.Time `json:"at"`
+	LastActive   time.Time `json:"last_active"`
+	Provisional  bool      `json:"provisional"`
+}
+
+var header = []string{"period", "rank", "team_id", "team", "rating", "rd", "volatility", "conservative",
+	"interval_low", "interval_high", "delta", "matches", "total_matches", "last_active", "provisional"}
+
+// BuildRows builds the Rows of a calculated RatingPeriod, ranked by the
+// PostRating of the Competitors. The conservative rating subtracts
+// conservativeK RDs.
+func BuildRows(ratingPeriod *glicko.RatingPeriod, teams map[int]*ranking.Team, conservativeK float64) []*Row {
+	rows := []*Row{}
+	for _, competitor := range ratingPeriod.Competitors {
+		if competitor.PostRating == nil {
+			continue
+		}
+
+		row := &Row{
+			Period:       ratingPeriod.ID,
+			TeamID:       competitor.ID,
+			Team:         teamName(teams, competitor.ID),
+			Delta:        competitor.PostRating.Rating - competitor.PreRating.Rating,
+			Matches:      len(competitor.Matches),
+			TotalMatches: len(competitor.Matches),
+			At:           ratingPeriod.EndDate,
+			LastActive:   ratingPeriod.EndDate,
+		}
+		row.setRating(competitor.PostRating, conservativeK)
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Rating != rows[j].Rating {
+			return rows[i].Rating > rows[j].Rating
+		}
+		return rows[i].TeamID < rows[j].TeamID
+	})
+	for i, row := range rows {
+		row.Rank = i + 1
+	}
+
+	return rows
+}
+
+// Write writes the Rows on the given Format.
+func Write(w io.Writer, format Format, rows []*Row) error {
+	switch format {
+	case FormatTable:
+		return writeTable(w, rows)
+	case FormatJSON:
+		return writeJSON(w, rows)
+	case FormatCSV:
+		return writeCSV(w, rows)
+	case FormatMarkdown:
+		return writeMarkdown(w, rows)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func writeTable(w io.Writer, rows []*Row) error {
+	ranked, provisional := splitProvisional(rows)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	writeTableRows(table, ranked)
+	if len(provisional) > 0 {
+		fmt.Fprintln(table, "\nPROVISIONAL\t")
+		writeTableRows(table, provisional)
+	}
+	return table.Flush()
+}
+
+func writeTableRows(table io.Writer, rows []*Row) {
+	fmt.Fprintln(table, "PERIOD\tRANK\tID\tTEAM\tRATING\tRD\t95% CI\tVOLATILITY\tCONSERVATIVE\tDELTA\tMATCHES\tTOTAL\t")
+	for _, row := range rows {
+		fmt.Fprintf(table, "%d\t%s\t%d\t%s\t%.2f\t%.2f\t%s\t%.6f\t%.2f\t%+.2f\t%d\t%d\t\n",
+			row.Period, formatRank(row), row.TeamID, row.Team, row.Rating, row.RD, formatInterval(row), row.Volatility,
+			row.Conservative, row.Delta, row.Matches, row.TotalMatches)
+	}
+}
+
+func writeJSON(w io.Writer, rows []*Row) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
+
+func writeCSV(w io.Writer, rows []*Row) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		err := writer.Write([]string{
+			strconv.Itoa(row.Period),
+			strconv.Itoa(row.Rank),
+			strconv.Itoa(row.TeamID),
+			row.Team,
+			formatFloat(row.Rating),
+			formatFloat(row.RD),
+			formatFloat(row.Volatility),
+			formatFloat(row.Conservative),
+			formatFloat(row.IntervalLow),
+			formatFloat(row.IntervalHigh),
+			formatFloat(row.Delta),
+			strconv.Itoa(row.Matches),
+			strconv.Itoa(row.TotalMatches),
+			row.LastActive.Format(time.RFC3339),
+			strconv.FormatBool(row.Provisional),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeMarkdown(w io.Writer, rows []*Row) error {
+	ranked, provisional := splitProvisional(rows)
+
+	lines := markdownTable(ranked)
+	if len(provisional) > 0 {
+		lines = append(lines, "", "**Provisional**", "")
+		lines = append(lines, markdownTable(provisional)...)
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func markdownTable(rows []*Row) []string {
+	lines := []string{
+		"| Period | Rank | Team | Rating | RD | 95% CI | Volatility | Conservative | Delta | Matches |",
+		"| ---: | ---: | --- | ---: | ---: | ---: | ---: | ---: | ---: | ---: |",
+	}
+	for _, row := range rows {
+		lines = append(lines, fmt.Sprintf("| %d | %s | %s | %.2f | %.2f | %s | %.6f | %.2f | %+.2f | %d |",
+			row.Period, formatRank(row), strings.ReplaceAll(row.Team, "|", `\|`),
+			row.Rating, row.RD, formatInterval(row), row.Volatility, row.Conservative, row.Delta, row.Matches))
+	}
+	return lines
+}
+
+// splitProvisional separates the Rows that are ranked from the provisional
+// ones, keeping their order.
+func splitProvisional(rows []*Row) (ranked, provisional []*Row) {
+	for _, row := range rows {
+		if row.Provisional {
+			provisional = append(provisional, row)
+		} else {
+			ranked = append(ranked, row)
+		}
+	}
+	return ranked, provisional
+}
+
+func formatRank(row *Row) string {
+	if row.Provisional {
+		return "-"
+	}
+	return strconv.Itoa(row.Rank)
+}
+
+// setRating fills the values of the Row derived from the Rating.
+func (row *Row) setRating(rating *glicko.Rating, conservativeK float64) {
+	row.Rating = rating.Rating
+	row.RD = rating.RatingDerivation
+	row.Volatility = rating.Volatility
+	row.Conservative = rating.Conservative(conservativeK)
+	row.IntervalLow, row.IntervalHigh = rating.ConfidenceInterval()
+}
+
+func formatInterval(row *Row) string {
+	return fmt.Sprintf("[%.0f, %.0f]", row.IntervalLow, row.IntervalHigh)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func teamName(teams map[int]*ranking.Team, id int) string {
+	if team, ok := teams[id]; ok {
+		return team.Name
+	}
+	return fmt.Sprintf("#%d", id)
+}
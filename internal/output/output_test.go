@@ -0,0 +1,313 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/history"
+	"github.com/augustoccesar/go-ranking/internal/prediction"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/internal/store"
+	"github.com/augustoccesar/go-ranking/internal/updater"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockRows() []*Row {
+	ratingPeriod := glicko.BuildRatingPeriodWithTime(1, time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 3, 8, 0, 0, 0, 0, time.UTC))
+	home := glicko.BuildRankableCompetitor(1, glicko.BuildDefaultRating())
+	away := glicko.BuildRankableCompetitor(2, glicko.BuildDefaultRating())
+	ratingPeriod.AddNewMatch(home, away, 2)
+	ratingPeriod.AddNewMatch(home, away, 2)
+	ratingPeriod.Calculate()
+
+	teams := map[int]*ranking.Team{
+		1: {ID: 1, Name: "Home"},
+		2: {ID: 2, Name: "Away | Team"},
+	}
+	return BuildRows(ratingPeriod, teams, glicko.DefaultConservativeK)
+}
+
+func TestBuildRows(t *testing.T) {
+	rows := mockRows()
+
+	assert.Equal(t, 2, len(rows))
+	assert.Equal(t, 2, rows[0].TeamID)
+	assert.Equal(t, 1, rows[0].Rank)
+	assert.Equal(t, 2, rows[0].Matches)
+	assert.Greater(t, rows[0].Delta, 0.0)
+	assert.Less(t, rows[1].Delta, 0.0)
+
+	// The 95% interval is centered on the rating and the conservative rating
+	// is bellow its lower end (2 RDs > 1.96 RDs).
+	assert.LessOrEqual(t, math.Abs(rows[0].Rating-(rows[0].IntervalLow+rows[0].IntervalHigh)/2), 0.000001)
+	assert.Less(t, rows[0].Conservative, rows[0].IntervalLow)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("csv")
+	assert.Nil(t, err)
+	assert.Equal(t, FormatCSV, format)
+
+	_, err = ParseFormat("xml")
+	assert.NotNil(t, err)
+}
+
+func TestWriteJSON(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, Write(buffer, FormatJSON, mockRows()))
+
+	rows := []*Row{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &rows))
+	assert.Equal(t, mockRows(), rows)
+}
+
+func TestWriteCSV(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, Write(buffer, FormatCSV, mockRows()))
+
+	records, err := csv.NewReader(buffer).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, header, records[0])
+	assert.Equal(t, []string{"1", "1", "2", "Away | Team"}, records[1][:4])
+}
+
+func TestWriteTableAndMarkdown(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, Write(buffer, FormatTable, mockRows()))
+	lines := strings.Split(strings.TrimRight(buffer.String(), "\n"), "\n")
+	assert.Equal(t, 3, len(lines))
+	// Right aligned, so every line has the same width.
+	assert.Equal(t, len(lines[0]), len(lines[1]))
+	assert.Equal(t, len(lines[0]), len(lines[2]))
+
+	buffer.Reset()
+	assert.Nil(t, Write(buffer, FormatMarkdown, mockRows()))
+	assert.Contains(t, buffer.String(), `| 1 | 1 | Away \| Team |`)
+}
+
+func TestStandings(t *testing.T) {
+	day := func(day int) time.Time { return time.Date(2019, 3, day, 0, 0, 0, 0, time.UTC) }
+	competitors := map[int]*glicko.RankableCompetitor{}
+	for id := 1; id <= 3; id++ {
+		competitors[id] = glicko.BuildRankableCompetitor(id, glicko.BuildDefaultRating())
+	}
+
+	first := glicko.BuildRatingPeriodWithTime(1, day(1), day(8))
+	first.AddNewMatch(competitors[1], competitors[2], 1)
+	first.AddNewMatch(competitors[1], competitors[3], 1)
+	first.Calculate()
+
+	second := glicko.BuildRatingPeriodWithTime(2, day(8).Add(time.Second), day(15))
+	second.AddNewMatch(
+		glicko.BuildRankableCompetitor(1, competitors[1].PostRating),
+		glicko.BuildRankableCompetitor(2, competitors[2].PostRating),
+		2,
+	)
+	second.Calculate()
+
+	standings := BuildStandings()
+	standings.Add(first, nil)
+	rows := standings.Add(second, nil)
+
+	assert.Equal(t, 2, len(rows))
+	assert.Equal(t, 1, rows[0].Matches)
+	assert.Equal(t, 5, rows[0].TotalMatches+rows[1].TotalMatches)
+
+	ranking := standings.Ranking(history.BuildHistory([]*glicko.RatingPeriod{first, second}).RankingAt(day(15)), nil)
+	assert.Equal(t, 3, len(ranking))
+	for _, row := range ranking {
+		assert.Equal(t, 2, row.Period)
+		if row.TeamID == 3 {
+			assert.Equal(t, 0, row.Matches)
+			assert.Equal(t, 1, row.TotalMatches)
+			assert.Equal(t, day(8), row.LastActive)
+		}
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	at := time.Date(2019, 3, 15, 0, 0, 0, 0, time.UTC)
+	rows := []*Row{
+		{Period: 1, TeamID: 1, Rating: 1600, RD: 300, Conservative: 1000, Delta: 100, TotalMatches: 1, At: at, LastActive: at},
+		{Period: 1, TeamID: 2, Rating: 1550, RD: 50, Conservative: 1450, Delta: 10, TotalMatches: 10, At: at, LastActive: at},
+		{Period: 1, TeamID: 3, Rating: 1500, RD: 60, Conservative: 1380, Delta: 20, TotalMatches: 10, At: at, LastActive: at.AddDate(0, 0, -30)},
+		{Period: 1, TeamID: 4, Rating: 1450, RD: 70, Conservative: 1310, Delta: 30, TotalMatches: 10, At: at, LastActive: at},
+	}
+
+	ranked := (&Leaderboard{}).Apply(rows)
+	assert.Equal(t, []int{1, 2, 3, 4}, teamIDs(ranked))
+	assert.Equal(t, 1, ranked[0].Rank)
+
+	leaderboard := &Leaderboard{
+		Top:          1,
+		MinMatches:   5,
+		MaxRD:        100,
+		ActiveWithin: 14 * 24 * time.Hour,
+		SortBy:       SortDelta,
+	}
+	filtered := leaderboard.Apply(rows)
+
+	// 4 has the best delta of the qualified Teams and 2 falls out of the top,
+	// 1 (few Matches, high RD) and 3 (inactive) are provisional.
+	assert.Equal(t, []int{4, 1, 3}, teamIDs(filtered))
+	assert.Equal(t, 1, filtered[0].Rank)
+	assert.False(t, filtered[0].Provisional)
+	assert.True(t, filtered[1].Provisional)
+	assert.Equal(t, 0, filtered[1].Rank)
+
+	_, err := ParseSortKey("volatility")
+	assert.NotNil(t, err)
+}
+
+func teamIDs(rows []*Row) []int {
+	ids := []int{}
+	for _, row := range rows {
+		ids = append(ids, row.TeamID)
+	}
+	return ids
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", Sparkline(nil))
+	assert.Equal(t, "▁▅█▁", Sparkline([]float64{1500, 1600, 1700, 1500}))
+	assert.Equal(t, "▅▅", Sparkline([]float64{1500, 1500}))
+}
+
+func TestCompare(t *testing.T) {
+	from := []*Row{
+		{TeamID: 1, Rank: 1, Rating: 1700},
+		{TeamID: 2, Rank: 2, Rating: 1600},
+		{TeamID: 3, Rank: 3, Rating: 1550},
+		{TeamID: 4, Rank: 0, Rating: 1500, Provisional: true},
+	}
+	to := []*Row{
+		{TeamID: 2, Rank: 1, Rating: 1720},
+		{TeamID: 1, Rank: 2, Rating: 1650},
+		{TeamID: 4, Rank: 3, Rating: 1540},
+		{TeamID: 5, Rank: 4, Rating: 1530},
+		{TeamID: 3, Rank: 0, Rating: 1560, Provisional: true},
+	}
+
+	diff := Compare(from, to, 1)
+
+	assert.Equal(t, []int{2, 1, 4, 5, 3}, diffTeamIDs(diff.Rows))
+	assert.Equal(t, 1, diff.Rows[0].RankChange)
+	assert.Equal(t, -1, diff.Rows[1].RankChange)
+	assert.Equal(t, 120.0, diff.Rows[0].Delta)
+
+	// 4 was provisional and 5 wasn't rated, 3 became provisional.
+	assert.Equal(t, []int{4, 5}, diffTeamIDs(diff.WithStatus(StatusNew)))
+	assert.Equal(t, []int{3}, diffTeamIDs(diff.WithStatus(StatusDropped)))
+	assert.Equal(t, 0.0, diff.Rows[3].Delta)
+
+	assert.Equal(t, []int{2}, diffTeamIDs(diff.Risers))
+	assert.Equal(t, []int{1}, diffTeamIDs(diff.Fallers))
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, WriteDiff(buffer, FormatCSV, diff))
+	records, err := csv.NewReader(buffer).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(records))
+	assert.Equal(t, []string{"3", "", "dropped", "3", "0"}, records[5][:5])
+
+	buffer.Reset()
+	assert.Nil(t, WriteDiff(buffer, FormatTable, diff))
+	assert.Contains(t, buffer.String(), "NEW")
+	assert.Contains(t, buffer.String(), "OUT")
+	assert.Contains(t, buffer.String(), "BIGGEST FALLERS")
+}
+
+func diffTeamIDs(rows []*DiffRow) []int {
+	ids := []int{}
+	for _, row := range rows {
+		ids = append(ids, row.TeamID)
+	}
+	return ids
+}
+
+func TestWriteSnapshotReport(t *testing.T) {
+	snapshot := &history.Snapshot{
+		CompetitorID:    2,
+		At:              time.Date(2019, 3, 20, 0, 0, 0, 0, time.UTC),
+		Rating:          glicko.BuildRating(1600, 80, 0.06),
+		Rank:            1,
+		LastPeriodID:    3,
+		InactivePeriods: 1,
+	}
+	report := BuildSnapshotReport(snapshot, map[int]*ranking.Team{2: {ID: 2, Name: "Liquid"}})
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, WriteSnapshotReport(buffer, FormatJSON, report))
+	decoded := &SnapshotReport{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), decoded))
+	assert.Equal(t, report, decoded)
+
+	buffer.Reset()
+	assert.Nil(t, WriteSnapshotReport(buffer, FormatTable, report))
+	assert.True(t, strings.HasPrefix(buffer.String(), "Liquid (#2) at 2019-03-20T00:00:00Z\n"))
+	assert.Contains(t, buffer.String(), "1 inactive period(s) since period 3")
+}
+
+func TestWritePredictions(t *testing.T) {
+	fixture := &ranking.Match{
+		ID:        7,
+		StartTime: time.Date(2019, 4, 1, 18, 0, 0, 0, time.UTC),
+		Home:      &ranking.Team{ID: 1, Name: "Astralis"},
+		Away:      &ranking.Team{ID: 2, Name: "Liquid"},
+	}
+	predicted, err := prediction.Predict(glicko.BuildRating(1700, 50, 0.06), glicko.BuildRating(1500, 50, 0.06), 3)
+	assert.Nil(t, err)
+	predictions := []*FixturePrediction{
+		BuildFixturePrediction(fixture, glicko.BuildRating(1700, 50, 0.06), glicko.BuildRating(1500, 50, 0.06), predicted),
+	}
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, WritePredictions(buffer, FormatJSON, predictions))
+	decoded := []*FixturePrediction{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &decoded))
+	assert.Equal(t, predictions, decoded)
+
+	buffer.Reset()
+	assert.Nil(t, WritePredictions(buffer, FormatTable, predictions))
+	assert.True(t, strings.HasPrefix(buffer.String(), "2019-04-01 18:00 - Astralis (1700) x Liquid (1500)\n"))
+	assert.Contains(t, buffer.String(), "Most likely: 2-0")
+}
+
+func TestWriteRecomputationReport(t *testing.T) {
+	recomputation := &updater.Recomputation{
+		PreviousVersion: 1,
+		Version:         &store.Version{ID: 2},
+		Periods:         []*glicko.RatingPeriod{{}, {}},
+		Movements: []*updater.Movement{
+			{CompetitorID: 1, Previous: glicko.BuildRating(1500, 80, 0.06), Current: glicko.BuildRating(1520, 80, 0.06), Delta: 20},
+			{CompetitorID: 2, Current: glicko.BuildRating(1480, 80, 0.06)},
+			{CompetitorID: 3, Previous: glicko.BuildRating(1510, 80, 0.06)},
+		},
+	}
+	report := BuildRecomputationReport(recomputation, map[int]*ranking.Team{1: {ID: 1, Name: "Astralis"}})
+	assert.Equal(t, 2, report.Version)
+	assert.Equal(t, 2, report.Periods)
+	assert.Equal(t, "#2", report.Movements[1].Team)
+	assert.Nil(t, report.Movements[1].Previous)
+	assert.Nil(t, report.Movements[2].Current)
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, WriteRecomputationReport(buffer, FormatJSON, report))
+	decoded := &RecomputationReport{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), decoded))
+	assert.Equal(t, report, decoded)
+
+	buffer.Reset()
+	assert.Nil(t, WriteRecomputationReport(buffer, FormatTable, report))
+	assert.Equal(t, "Version 2 created from version 1 (2 period(s) recalculated).\nRating movements:\n"+
+		"\tAstralis - 1500.000000 -> 1520.000000 (+20.000000)\n"+
+		"\t#2 - new: 1480.000000\n"+
+		"\t#3 - removed (was 1510.000000)\n", buffer.String())
+}
@@ -0,0 +1,67 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/prediction"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// FixturePrediction is the struct that holds the Prediction of a scheduled
+// Match with the ratings it is based on.
+type FixturePrediction struct {
+	MatchID    int                    `json:"match_id,omitempty"`
+	StartTime  time.Time              `json:"start_time"`
+	HomeID     int                    `json:"home_id"`
+	Home       string                 `json:"home"`
+	HomeRating float64                `json:"home_rating"`
+	AwayID     int                    `json:"away_id"`
+	Away       string                 `json:"away"`
+	AwayRating float64                `json:"away_rating"`
+	Prediction *prediction.Prediction `json:"prediction"`
+}
+
+// BuildFixturePrediction builds the FixturePrediction of a scheduled Match.
+func BuildFixturePrediction(fixture *ranking.Match, homeRating, awayRating *glicko.Rating, predicted *prediction.Prediction) *FixturePrediction {
+	return &FixturePrediction{
+		MatchID:    fixture.ID,
+		StartTime:  fixture.StartTime,
+		HomeID:     fixture.Home.ID,
+		Home:       fixture.Home.Name,
+		HomeRating: homeRating.Rating,
+		AwayID:     fixture.Away.ID,
+		Away:       fixture.Away.Name,
+		AwayRating: awayRating.Rating,
+		Prediction: predicted,
+	}
+}
+
+// WritePredictions writes the FixturePredictions as JSON with FormatJSON and
+// as text otherwise.
+func WritePredictions(w io.Writer, format Format, predictions []*FixturePrediction) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(predictions)
+	}
+
+	for _, predicted := range predictions {
+		mostLikely := predicted.Prediction.MostLikely()
+
+		fmt.Fprintf(w, "%s - %s (%.0f) x %s (%.0f)\n", predicted.StartTime.Format("2006-01-02 15:04"),
+			predicted.Home, predicted.HomeRating, predicted.Away, predicted.AwayRating)
+		fmt.Fprintf(w, "\tWin probability: %.1f%% x %.1f%%\n",
+			predicted.Prediction.HomeWinProbability*100, predicted.Prediction.AwayWinProbability*100)
+		_, err := fmt.Fprintf(w, "\tExpected maps: %.2f x %.2f - Most likely: %d-%d (%.1f%%)\n",
+			predicted.Prediction.ExpectedHomeMaps, predicted.Prediction.ExpectedAwayMaps,
+			mostLikely.Home, mostLikely.Away, mostLikely.Probability*100)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/internal/updater"
+)
+
+// RecomputationReport is the serializable form of an updater.Recomputation.
+type RecomputationReport struct {
+	Version         int               `json:"version"`
+	PreviousVersion int               `json:"previous_version"`
+	Periods         int               `json:"periods"`
+	Movements       []*MovementReport `json:"movements"`
+}
+
+// MovementReport is the serializable form of an updater.Movement. Previous is
+// nil for a new Team and Current is nil for a removed one.
+type MovementReport struct {
+	TeamID   int      `json:"team_id"`
+	Team     string   `json:"team"`
+	Previous *float64 `json:"previous"`
+	Current  *float64 `json:"current"`
+	Delta    float64  `json:"delta"`
+}
+
+// BuildRecomputationReport builds the RecomputationReport of the new version,
+// keeping the order of the movements.
+func BuildRecomputationReport(recomputation *updater.Recomputation, teams map[int]*ranking.Team) *RecomputationReport {
+	report := &RecomputationReport{
+		Version:         recomputation.Version.ID,
+		PreviousVersion: recomputation.PreviousVersion,
+		Periods:         len(recomputation.Periods),
+		Movements:       []*MovementReport{},
+	}
+
+	for _, movement := range recomputation.Movements {
+		movementReport := &MovementReport{
+			TeamID: movement.CompetitorID,
+			Team:   teamName(teams, movement.CompetitorID),
+			Delta:  movement.Delta,
+		}
+		if movement.Previous != nil {
+			previous := movement.Previous.Rating
+			movementReport.Previous = &previous
+		}
+		if movement.Current != nil {
+			current := movement.Current.Rating
+			movementReport.Current = &current
+		}
+		report.Movements = append(report.Movements, movementReport)
+	}
+
+	return report
+}
+
+// WriteRecomputationReport writes the RecomputationReport as JSON with
+// FormatJSON and as text otherwise.
+func WriteRecomputationReport(w io.Writer, format Format, report *RecomputationReport) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Fprintf(w, "Version %d created from version %d (%d period(s) recalculated).\n",
+		report.Version, report.PreviousVersion, report.Periods)
+	_, err := fmt.Fprintf(w, "Rating movements:\n")
+
+	for _, movement := range report.Movements {
+		switch {
+		case movement.Previous == nil:
+			_, err = fmt.Fprintf(w, "\t%s - new: %f\n", movement.Team, *movement.Current)
+		case movement.Current == nil:
+			_, err = fmt.Fprintf(w, "\t%s - removed (was %f)\n", movement.Team, *movement.Previous)
+		default:
+			_, err = fmt.Fprintf(w, "\t%s - %f -> %f (%+f)\n", movement.Team, *movement.Previous, *movement.Current, movement.Delta)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
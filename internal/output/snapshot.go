@@ -0,0 +1,56 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/history"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+)
+
+// SnapshotReport is the serializable form of a history.Snapshot.
+type SnapshotReport struct {
+	TeamID          int       `json:"team_id"`
+	Team            string    `json:"team"`
+	At              time.Time `json:"at"`
+	Rank            int       `json:"rank"`
+	Rating          float64   `json:"rating"`
+	RD              float64   `json:"rd"`
+	Volatility      float64   `json:"volatility"`
+	LastPeriod      int       `json:"last_period"`
+	InactivePeriods int       `json:"inactive_periods"`
+}
+
+// BuildSnapshotReport builds the SnapshotReport of the state of a Team.
+func BuildSnapshotReport(snapshot *history.Snapshot, teams map[int]*ranking.Team) *SnapshotReport {
+	return &SnapshotReport{
+		TeamID:          snapshot.CompetitorID,
+		Team:            teamName(teams, snapshot.CompetitorID),
+		At:              snapshot.At,
+		Rank:            snapshot.Rank,
+		Rating:          snapshot.Rating.Rating,
+		RD:              snapshot.Rating.RatingDerivation,
+		Volatility:      snapshot.Rating.Volatility,
+		LastPeriod:      snapshot.LastPeriodID,
+		InactivePeriods: snapshot.InactivePeriods,
+	}
+}
+
+// WriteSnapshotReport writes the SnapshotReport as JSON with FormatJSON and as
+// text otherwise.
+func WriteSnapshotReport(w io.Writer, format Format, report *SnapshotReport) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Fprintf(w, "%s (#%d) at %s\n", report.Team, report.TeamID, report.At.Format(time.RFC3339))
+	fmt.Fprintf(w, "\tRank: #%d\n", report.Rank)
+	fmt.Fprintf(w, "\tRating: %f\n", report.Rating)
+	fmt.Fprintf(w, "\tRD: %f (%d inactive period(s) since period %d)\n", report.RD, report.InactivePeriods, report.LastPeriod)
+	_, err := fmt.Fprintf(w, "\tVolatility: %f\n", report.Volatility)
+	return err
+}
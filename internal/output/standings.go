@@ -0,0 +1,82 @@
+package output
+
+import (
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/history"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// Standings is the struct that follows the calculated RatingPeriods, in
+// order, to know how many Matches each Team played and when it last played.
+type Standings struct {
+	ConservativeK float64 // RDs subtracted on the conservative rating.
+
+	period     int // Id of the last period added.
+	matches    map[int]int
+	lastActive map[int]time.Time
+	last       map[int]*Row // Row of the last period each Team played.
+}
+
+// BuildStandings builds empty Standings.
+func BuildStandings() *Standings {
+	return &Standings{
+		ConservativeK: glicko.DefaultConservativeK,
+		matches:       map[int]int{},
+		lastActive:    map[int]time.Time{},
+		last:          map[int]*Row{},
+	}
+}
+
+// Add adds the next calculated RatingPeriod and returns its Rows, with the
+// totals of the Teams until it.
+func (s *Standings) Add(ratingPeriod *glicko.RatingPeriod, teams map[int]*ranking.Team) []*Row {
+	s.period = ratingPeriod.ID
+
+	rows := BuildRows(ratingPeriod, teams, s.ConservativeK)
+	for _, row := range rows {
+		s.matches[row.TeamID] += row.Matches
+		s.lastActive[row.TeamID] = ratingPeriod.EndDate
+		s.last[row.TeamID] = row
+
+		row.TotalMatches = s.matches[row.TeamID]
+	}
+	return rows
+}
+
+// Ranking builds the Rows of a point-in-time ranking of the History, as of the
+// last period added. The delta and the Matches are the ones of that period,
+// so the Teams that didn't play it have none.
+func (s *Standings) Ranking(snapshots []*history.Snapshot, teams map[int]*ranking.Team) []*Row {
+	rows := []*Row{}
+	for _, snapshot := range snapshots {
+		row := &Row{
+			Period:       s.period,
+			Rank:         snapshot.Rank,
+			TeamID:       snapshot.CompetitorID,
+			Team:         teamName(teams, snapshot.CompetitorID),
+			TotalMatches: s.matches[snapshot.CompetitorID],
+			At:           snapshot.At,
+			LastActive:   s.lastActive[snapshot.CompetitorID],
+		}
+		row.setRating(snapshot.Rating, s.ConservativeK)
+		if last, ok := s.last[snapshot.CompetitorID]; ok && snapshot.LastPeriodID == last.Period && snapshot.InactivePeriods == 0 {
+			row.Delta = last.Delta
+			row.Matches = last.Matches
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// RankingAt adds the periods of the History closed until the moment to the
+// (empty) Standings and builds the Rows of its point-in-time ranking then.
+func (s *Standings) RankingAt(h *history.History, teams map[int]*ranking.Team, at time.Time) []*Row {
+	for _, ratingPeriod := range h.Periods() {
+		if !ratingPeriod.EndDate.After(at) {
+			s.Add(ratingPeriod, teams)
+		}
+	}
+	return s.Ranking(h.RankingAt(at), teams)
+}
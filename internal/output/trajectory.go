@@ -0,0 +1,174 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/history"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+)
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws the values as a line of block characters, from the lowest
+// value (▁) to the highest (█).
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, value := range values {
+		low = math.Min(low, value)
+		high = math.Max(high, value)
+	}
+
+	line := make([]rune, len(values))
+	for i, value := range values {
+		level := len(sparks) / 2
+		if high > low {
+			level = int(math.Round((value - low) / (high - low) * float64(len(sparks)-1)))
+		}
+		line[i] = sparks[level]
+	}
+	return string(line)
+}
+
+// TrajectoryPoint is the serializable form of a history.Point.
+type TrajectoryPoint struct {
+	Period     int            `json:"period"`
+	StartDate  time.Time      `json:"start_date"`
+	EndDate    time.Time      `json:"end_date"`
+	Played     bool           `json:"played"`
+	Rank       int            `json:"rank"`
+	PreRating  float64        `json:"pre_rating,omitempty"`
+	Rating     float64        `json:"rating"`
+	RD         float64        `json:"rd"`
+	Volatility float64        `json:"volatility"`
+	Matches    []*MatchReport `json:"matches"`
+}
+
+// MatchReport is the serializable form of a history.MatchResult.
+type MatchReport struct {
+	MatchID        int     `json:"match_id,omitempty"`
+	OpponentID     int     `json:"opponent_id"`
+	Opponent       string  `json:"opponent"`
+	OpponentRating float64 `json:"opponent_rating"`
+	OpponentRD     float64 `json:"opponent_rd"`
+	Score          float64 `json:"score"`
+}
+
+// TeamReport is the struct that holds the Trajectory of a Team.
+type TeamReport struct {
+	TeamID    int                `json:"team_id"`
+	Team      string             `json:"team"`
+	Sparkline string             `json:"sparkline"`
+	Points    []*TrajectoryPoint `json:"periods"`
+}
+
+// BuildTeamReport builds the TeamReport of the Trajectory of a Team.
+func BuildTeamReport(teamID int, points []*history.Point, teams map[int]*ranking.Team) *TeamReport {
+	report := &TeamReport{
+		TeamID: teamID,
+		Team:   teamName(teams, teamID),
+		Points: []*TrajectoryPoint{},
+	}
+
+	ratings := []float64{}
+	for _, point := range points {
+		trajectoryPoint := &TrajectoryPoint{
+			Period:     point.PeriodID,
+			StartDate:  point.StartDate,
+			EndDate:    point.EndDate,
+			Played:     point.Played,
+			Rank:       point.Rank,
+			Rating:     point.PostRating.Rating,
+			RD:         point.PostRating.RatingDerivation,
+			Volatility: point.PostRating.Volatility,
+			Matches:    []*MatchReport{},
+		}
+		if point.PreRating != nil {
+			trajectoryPoint.PreRating = point.PreRating.Rating
+		}
+		for _, match := range point.Matches {
+			trajectoryPoint.Matches = append(trajectoryPoint.Matches, &MatchReport{
+				MatchID:        match.MatchID,
+				OpponentID:     match.OpponentID,
+				Opponent:       teamName(teams, match.OpponentID),
+				OpponentRating: match.OpponentRating.Rating,
+				OpponentRD:     match.OpponentRating.RatingDerivation,
+				Score:          match.Score,
+			})
+		}
+
+		report.Points = append(report.Points, trajectoryPoint)
+		ratings = append(ratings, point.PostRating.Rating)
+	}
+	report.Sparkline = Sparkline(ratings)
+
+	return report
+}
+
+// WriteTeamReport writes the TeamReport as JSON with FormatJSON and as text
+// otherwise.
+func WriteTeamReport(w io.Writer, format Format, report *TeamReport) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Fprintf(w, "%s (#%d)\n", report.Team, report.TeamID)
+	if len(report.Points) == 0 {
+		_, err := fmt.Fprintln(w, "No rated periods.")
+		return err
+	}
+	// The first Point is always a played period, so it has a PreRating.
+	first, last := report.Points[0], report.Points[len(report.Points)-1]
+	fmt.Fprintf(w, "%s  %.0f -> %.0f (%s - %s)\n\n", report.Sparkline, first.PreRating, last.Rating,
+		first.StartDate.Format("2006-01-02"), last.EndDate.Format("2006-01-02"))
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "PERIOD\tDATES\tRANK\tPRE\tPOST\tDELTA\tRD\tVOLATILITY\tMATCHES")
+	for _, point := range report.Points {
+		if !point.Played {
+			fmt.Fprintf(table, "%d\t%s\t#%d\t\t%.2f\t\t%.2f\t%.6f\t(inactive)\n", point.Period, formatDates(point),
+				point.Rank, point.Rating, point.RD, point.Volatility)
+			continue
+		}
+
+		for i, match := range point.Matches {
+			if i > 0 {
+				fmt.Fprintf(table, "\t\t\t\t\t\t\t\t%s\n", formatMatch(match))
+				continue
+			}
+			fmt.Fprintf(table, "%d\t%s\t#%d\t%.2f\t%.2f\t%+.2f\t%.2f\t%.6f\t%s\n", point.Period, formatDates(point),
+				point.Rank, point.PreRating, point.Rating, point.Rating-point.PreRating, point.RD, point.Volatility,
+				formatMatch(match))
+		}
+	}
+	return table.Flush()
+}
+
+func formatDates(point *TrajectoryPoint) string {
+	return point.StartDate.Format("2006-01-02") + " - " + point.EndDate.Format("2006-01-02")
+}
+
+func formatMatch(match *MatchReport) string {
+	return fmt.Sprintf("%s vs %s (%.0f ± %.0f)", resultLetter(match.Score), match.Opponent, match.OpponentRating, match.OpponentRD)
+}
+
+// resultLetter returns W, T or L for the score of a Match.
+func resultLetter(score float64) string {
+	switch score {
+	case 1:
+		return "W"
+	case 0.5:
+		return "T"
+	}
+	return "L"
+}
@@ -0,0 +1,122 @@
+package prediction
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// Scoreline is the struct that holds one possible final score of a series.
+type Scoreline struct {
+	Home        int     `json:"home"`
+	Away        int     `json:"away"`
+	Probability float64 `json:"probability"`
+}
+
+// Prediction is the struct that holds the predicted outcome of a Match played
+// as a best-of-N series.
+type Prediction struct {
+	BestOf             int          `json:"best_of"`
+	HomeWinProbability float64      `json:"home_win_probability"`
+	AwayWinProbability float64      `json:"away_win_probability"`
+	MapWinProbability  float64      `json:"map_win_probability"` // Probability of Home winning a single map.
+	Scorelines         []*Scoreline `json:"scorelines"`
+	ExpectedHomeMaps   float64      `json:"expected_home_maps"`
+	ExpectedAwayMaps   float64      `json:"expected_away_maps"`
+}
+
+// Predict calculates the Prediction of a series between two ratings.
+//
+// The ratings are built from series results, so the win probability given by
+// Glicko2 is the one of the whole series. The per map probability is the one
+// that, played bestOf times, results in that same series probability, and is
+// then used to distribute the scorelines.
+func Predict(home, away *glicko.Rating, bestOf int) (*Prediction, error) {
+	if bestOf < 1 || bestOf%2 == 0 {
+		return nil, fmt.Errorf("prediction: best of must be a positive odd number, got %d", bestOf)
+	}
+
+	seriesProbability := glicko.ExpectedScore(home, away)
+	mapProbability := mapWinProbability(seriesProbability, bestOf)
+
+	prediction := &Prediction{
+		BestOf:             bestOf,
+		HomeWinProbability: seriesProbability,
+		AwayWinProbability: 1 - seriesProbability,
+		MapWinProbability:  mapProbability,
+		Scorelines:         scorelines(mapProbability, bestOf),
+	}
+
+	for _, scoreline := range prediction.Scorelines {
+		prediction.ExpectedHomeMaps += float64(scoreline.Home) * scoreline.Probability
+		prediction.ExpectedAwayMaps += float64(scoreline.Away) * scoreline.Probability
+	}
+
+	return prediction, nil
+}
+
+// MostLikely returns the Scoreline with the highest probability.
+func (p *Prediction) MostLikely() *Scoreline {
+	return p.Scorelines[0]
+}
+
+// SeriesWinProbability is the probability of winning a best-of-N series when
+// each map is won with probability p.
+func SeriesWinProbability(p float64, bestOf int) float64 {
+	probability := 0.0
+	for _, scoreline := range scorelines(p, bestOf) {
+		if scoreline.Home > scoreline.Away {
+			probability += scoreline.Probability
+		}
+	}
+	return probability
+}
+
+// scorelines lists every possible final score of the series, most likely
+// first.
+func scorelines(p float64, bestOf int) []*Scoreline {
+	toWin := (bestOf + 1) / 2
+	result := []*Scoreline{}
+
+	// The winner always takes the last map, the loser won `lost` of the
+	// previous ones in any order.
+	for lost := 0; lost < toWin; lost++ {
+		combinations := binomial(toWin-1+lost, lost)
+		result = append(result,
+			&Scoreline{Home: toWin, Away: lost, Probability: combinations * math.Pow(p, float64(toWin)) * math.Pow(1-p, float64(lost))},
+			&Scoreline{Home: lost, Away: toWin, Probability: combinations * math.Pow(1-p, float64(toWin)) * math.Pow(p, float64(lost))},
+		)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Probability > result[j].Probability
+	})
+
+	return result
+}
+
+// mapWinProbability finds (by bisection, since the series probability always
+// grows with the map probability) the map probability that results in the
+// given series probability.
+func mapWinProbability(seriesProbability float64, bestOf int) float64 {
+	low, high := 0.0, 1.0
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if SeriesWinProbability(mid, bestOf) < seriesProbability {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
+
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package prediction
+
+import (
+	"math"
+	"testing"
+
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredictEvenRatings(t *testing.T) {
+	prediction, err := Predict(glicko.BuildDefaultRating(), glicko.BuildDefaultRating(), 3)
+
+	assert.Nil(t, err)
+	assert.LessOrEqual(t, math.Abs(0.5-prediction.HomeWinProbability), 0.0001)
+	assert.LessOrEqual(t, math.Abs(0.5-prediction.MapWinProbability), 0.0001)
+	assert.Equal(t, 4, len(prediction.Scorelines))
+	assert.LessOrEqual(t, math.Abs(prediction.ExpectedHomeMaps-prediction.ExpectedAwayMaps), 0.0001)
+}
+
+func TestPredictFavorite(t *testing.T) {
+	favorite := glicko.BuildRating(1800, 50, 0.06)
+	underdog := glicko.BuildRating(1500, 50, 0.06)
+
+	prediction, _ := Predict(favorite, underdog, 3)
+
+	assert.Greater(t, prediction.HomeWinProbability, 0.8)
+	assert.Equal(t, 2, prediction.MostLikely().Home)
+	assert.Equal(t, 0, prediction.MostLikely().Away)
+
+	// The map probability must reproduce the series probability.
+	assert.LessOrEqual(t, math.Abs(prediction.HomeWinProbability-SeriesWinProbability(prediction.MapWinProbability, 3)), 0.0001)
+
+	total := 0.0
+	for _, scoreline := range prediction.Scorelines {
+		total += scoreline.Probability
+	}
+	assert.LessOrEqual(t, math.Abs(1-total), 0.0001)
+}
+
+func TestPredictInvalidBestOf(t *testing.T) {
+	_, err := Predict(glicko.BuildDefaultRating(), glicko.BuildDefaultRating(), 2)
+
+	assert.NotNil(t, err)
+}
+
+func TestSeriesWinProbability(t *testing.T) {
+	assert.LessOrEqual(t, math.Abs(0.6-SeriesWinProbability(0.6, 1)), 0.0001)
+	// Bo3: p² + 2p²(1-p)
+	assert.LessOrEqual(t, math.Abs(0.648-SeriesWinProbability(0.6, 3)), 0.0001)
+}
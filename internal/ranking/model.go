@@ -0,0 +1,90 @@
+package ranking
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/spider/thescore"
+)
+
+// Team is the struct that represents a Team independently of the source it
+// was fetched from.
+type Team struct {
+	ID   int
+	Name string
+}
+
+// FindTeam finds a Team by its id or, case insensitively, by its name.
+func FindTeam(query string, teams map[int]*Team) (int, error) {
+	if id, err := strconv.Atoi(query); err == nil {
+		if _, ok := teams[id]; ok {
+			return id, nil
+		}
+		return 0, fmt.Errorf("team %d not found", id)
+	}
+
+	for id, team := range teams {
+		if strings.EqualFold(team.Name, query) {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("team %q not found", query)
+}
+
+// Match is the struct that represents a finished (or scheduled) Match
+// independently of the source it was fetched from. Winner is nil for ties and
+// for Matches that didn't happen yet.
+type Match struct {
+	ID        int
+	Home      *Team
+	Away      *Team
+	Winner    *Team
+	HomeScore int
+	AwayScore int
+	StartTime time.Time
+}
+
+// WinnerID returns the id of the Winner, or -1 (what glicko expects) for a
+// tie.
+func (m *Match) WinnerID() int {
+	if m.Winner == nil {
+		return -1
+	}
+	return m.Winner.ID
+}
+
+// FromTheScore converts the Matches fetched from TheScore. Matches with Teams
+// still undefined are dropped.
+func FromTheScore(matches []*thescore.Match) []*Match {
+	teams := map[int]*Team{}
+	team := func(t *thescore.Team) *Team {
+		if t == nil {
+			return nil
+		}
+		if _, ok := teams[t.ID]; !ok {
+			teams[t.ID] = &Team{ID: t.ID, Name: t.Name}
+		}
+		return teams[t.ID]
+	}
+
+	converted := []*Match{}
+	for _, match := range matches {
+		if match.Home == nil || match.Away == nil {
+			continue
+		}
+
+		converted = append(converted, &Match{
+			ID:        match.ID,
+			Home:      team(match.Home),
+			Away:      team(match.Away),
+			Winner:    team(match.Winner),
+			HomeScore: match.HomeScore,
+			AwayScore: match.AwayScore,
+			StartTime: match.StartTime,
+		})
+	}
+
+	return converted
+}
@@ -0,0 +1,185 @@
+package ranking
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/lineage"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// Ranker is the struct that splits a range of Matches into RatingPeriods and
+// carries the ratings of the Teams from one period to the next.
+type Ranker struct {
+	StartDate      time.Time
+	EndDate        time.Time
+	PeriodDuration int // In days.
+	Resolver       *lineage.Resolver
+
+	// SystemConstant is the τ used on the periods, zero keeps the default
+	// of glicko.BuildRatingPeriod.
+	SystemConstant float64
+	// DefaultRating is the Rating of the Competitors that never played, nil
+	// uses glicko.BuildDefaultRating.
+	DefaultRating *glicko.Rating
+	// Parallel calculates the Competitors of each period on GOMAXPROCS
+	// goroutines, with the same results.
+	Parallel bool
+
+	// ClosedOnly makes the Ranker skip the last period when it ends after
+	// the EndDate, so only periods that are over get calculated.
+	ClosedOnly bool
+
+	// OnPeriod, when set, is called after each RatingPeriod is calculated
+	// (e.g. to persist it). An error stops the rating.
+	OnPeriod func(ratingPeriod *glicko.RatingPeriod) error
+
+	Periods []*glicko.RatingPeriod
+	Teams   map[int]*Team          // Latest Team seen for each lineage id.
+	Ratings map[int]*glicko.Rating // Latest Rating for each lineage id.
+
+	lastPeriod *glicko.RatingPeriod
+}
+
+// BuildRanker builds a Ranker for a date range split in periods of
+// periodDuration days.
+func BuildRanker(startDate, endDate time.Time, periodDuration int, resolver *lineage.Resolver) *Ranker {
+	return &Ranker{
+		StartDate:      startDate,
+		EndDate:        endDate,
+		PeriodDuration: periodDuration,
+		Resolver:       resolver,
+		Periods:        []*glicko.RatingPeriod{},
+		Teams:          map[int]*Team{},
+		Ratings:        map[int]*glicko.Rating{},
+	}
+}
+
+// Resume makes the Ranker continue from a RatingPeriod calculated before
+// (e.g. loaded from a Store): the periods start right after its end, the ids
+// continue from its id and the Competitors start from the given ratings.
+func (r *Ranker) Resume(lastPeriod *glicko.RatingPeriod, ratings map[int]*glicko.Rating) {
+	r.lastPeriod = lastPeriod
+	for id, rating := range ratings {
+		r.Ratings[id] = rating
+	}
+}
+
+// Rate calculates all the RatingPeriods of the range using the given Matches.
+// The resulting periods are also kept on the Ranker. Matches whose Teams
+// resolve to the same lineage aren't rated.
+func (r *Ranker) Rate(matches []*Match) ([]*glicko.RatingPeriod, error) {
+	if r.PeriodDuration <= 0 {
+		return nil, fmt.Errorf("ranking: invalid period duration %d", r.PeriodDuration)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].StartTime.Before(matches[j].StartTime)
+	})
+
+	previousPeriod := r.lastPeriod
+	for {
+		ratingPeriodID := 1
+		ratingStartDate := r.StartDate
+		if previousPeriod != nil {
+			ratingPeriodID = previousPeriod.ID + 1
+			ratingStartDate = previousPeriod.EndDate.Add(1 * time.Second)
+		}
+		ratingEndDate := ratingStartDate.AddDate(0, 0, r.PeriodDuration)
+
+		if !ratingStartDate.Before(r.EndDate) || (r.ClosedOnly && ratingEndDate.After(r.EndDate)) {
+			break
+		}
+
+		ratingPeriod := glicko.BuildRatingPeriodWithTime(ratingPeriodID, ratingStartDate, ratingEndDate)
+		if r.SystemConstant != 0 {
+			ratingPeriod.SystemConstant = r.SystemConstant
+		}
+		for _, match := range matches {
+			if match.StartTime.Before(ratingPeriod.EndDate) && match.StartTime.After(ratingPeriod.StartDate) {
+				if err := r.addMatch(ratingPeriod, match); err != nil {
+					return r.Periods, err
+				}
+			}
+		}
+
+		if r.Parallel {
+			ratingPeriod.CalculateParallel(0)
+		} else {
+			ratingPeriod.Calculate()
+		}
+
+		for _, competitor := range ratingPeriod.Competitors {
+			r.Ratings[competitor.ID] = competitor.PostRating
+		}
+
+		r.Periods = append(r.Periods, ratingPeriod)
+		previousPeriod = ratingPeriod
+
+		if r.OnPeriod != nil {
+			if err := r.OnPeriod(ratingPeriod); err != nil {
+				return r.Periods, err
+			}
+		}
+	}
+
+	return r.Periods, nil
+}
+
+// Rating returns the latest Rating of a Team (resolving its lineage at the
+// given moment), or the default one if it never played.
+func (r *Ranker) Rating(teamID int, at time.Time) *glicko.Rating {
+	if rating, ok := r.Ratings[r.Resolver.Resolve(teamID, at)]; ok {
+		return rating
+	}
+	return r.defaultRating()
+}
+
+// defaultRating builds a new Rating for a Competitor that never played.
+func (r *Ranker) defaultRating() *glicko.Rating {
+	if r.DefaultRating == nil {
+		return glicko.BuildDefaultRating()
+	}
+	return glicko.BuildRating(r.DefaultRating.Rating, r.DefaultRating.RatingDerivation, r.DefaultRating.Volatility)
+}
+
+// addMatch registers the Match on the RatingPeriod, reusing the Competitors
+// already on it and starting new ones from their latest Rating. Matches
+// between Teams of the same lineage are skipped.
+func (r *Ranker) addMatch(ratingPeriod *glicko.RatingPeriod, match *Match) error {
+	// Resolve the ids before anything else, so Teams that rebranded keep the
+	// rating of their lineage.
+	homeID := r.Resolver.Resolve(match.Home.ID, match.StartTime)
+	awayID := r.Resolver.Resolve(match.Away.ID, match.StartTime)
+	winnerID := match.WinnerID()
+	if winnerID != -1 {
+		winnerID = r.Resolver.Resolve(winnerID, match.StartTime)
+	}
+	// Both sides on the same lineage (e.g. an old roster against the one that
+	// took its place) would be a Competitor playing itself.
+	if homeID == awayID {
+		return nil
+	}
+
+	// Keeps the latest name seen for the lineage.
+	r.Teams[homeID] = match.Home
+	r.Teams[awayID] = match.Away
+
+	rankableMatch := glicko.BuildRankableMatch(r.competitor(ratingPeriod, homeID), r.competitor(ratingPeriod, awayID), winnerID)
+	rankableMatch.ID = match.ID
+	return ratingPeriod.AddBuiltMatch(rankableMatch)
+}
+
+// competitor finds the Competitor on the RatingPeriod or builds a new one.
+func (r *Ranker) competitor(ratingPeriod *glicko.RatingPeriod, id int) *glicko.RankableCompetitor {
+	if competitor := ratingPeriod.Competitor(id); competitor != nil {
+		return competitor
+	}
+
+	rating, ok := r.Ratings[id]
+	if !ok {
+		rating = r.defaultRating()
+	}
+	return glicko.BuildRankableCompetitor(id, rating)
+}
@@ -0,0 +1,97 @@
+package ranking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/lineage"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockMatches() []*Match {
+	teamA := &Team{ID: 1, Name: "A"}
+	teamB := &Team{ID: 2, Name: "B"}
+	teamC := &Team{ID: 3, Name: "C"}
+	teamD := &Team{ID: 4, Name: "D (former C)"}
+
+	return []*Match{
+		{ID: 1, Home: teamA, Away: teamB, Winner: teamA, StartTime: time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Home: teamA, Away: teamC, Winner: teamC, StartTime: time.Date(2019, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{ID: 3, Home: teamB, Away: teamD, Winner: teamD, StartTime: time.Date(2019, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{ID: 4, Home: teamA, Away: teamB, Winner: nil, StartTime: time.Date(2019, 3, 11, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+func TestRate(t *testing.T) {
+	startDate := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2019, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	ranker := BuildRanker(startDate, endDate, 7, nil)
+	periods, err := ranker.Rate(mockMatches())
+
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, len(periods))
+	assert.Equal(t, 3, len(periods[0].Competitors))
+	assert.Equal(t, 3, len(periods[1].Competitors))
+	assert.Equal(t, 4, len(ranker.Ratings))
+
+	// Ratings are carried from the first period to the second.
+	for _, competitor := range periods[1].Competitors {
+		if competitor.ID == 1 {
+			assert.Equal(t, periods[0].Competitors[0].PostRating, competitor.PreRating)
+		}
+	}
+}
+
+func TestRateWithResolver(t *testing.T) {
+	startDate := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2019, 3, 15, 0, 0, 0, 0, time.UTC)
+	resolver := lineage.BuildResolver([]*lineage.Alias{
+		{FromID: 3, ToID: 4, EffectiveDate: time.Date(2019, 3, 5, 0, 0, 0, 0, time.UTC)},
+	})
+
+	ranker := BuildRanker(startDate, endDate, 7, resolver)
+	periods, err := ranker.Rate(mockMatches())
+
+	assert.Nil(t, err)
+
+	assert.Equal(t, 3, len(ranker.Ratings))
+	assert.Equal(t, "D (former C)", ranker.Teams[3].Name)
+
+	for _, competitor := range periods[1].Competitors {
+		if competitor.ID == 3 {
+			assert.Equal(t, ranker.Periods[0].Competitors[2].PostRating, competitor.PreRating)
+		}
+	}
+	assert.Equal(t, ranker.Ratings[3], ranker.Rating(4, endDate))
+}
+
+func TestRateSameLineage(t *testing.T) {
+	startDate := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2019, 3, 15, 0, 0, 0, 0, time.UTC)
+	resolver := lineage.BuildResolver([]*lineage.Alias{
+		{FromID: 2, ToID: 4, EffectiveDate: time.Date(2019, 3, 5, 0, 0, 0, 0, time.UTC)},
+	})
+
+	ranker := BuildRanker(startDate, endDate, 7, resolver)
+	periods, err := ranker.Rate(mockMatches())
+
+	assert.Nil(t, err)
+
+	// B x D (former B) is skipped, only A x B is left on the second period.
+	assert.Equal(t, 1, len(periods[1].Matches))
+	assert.Equal(t, 4, periods[1].Matches[0].ID)
+	assert.Equal(t, 2, len(periods[1].Competitors))
+	for _, match := range periods[1].Matches {
+		assert.NotEqual(t, match.Home.ID, match.Away.ID)
+	}
+}
+
+func TestRateInvalidDuration(t *testing.T) {
+	startDate := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2019, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	_, err := BuildRanker(startDate, endDate, 0, nil).Rate(mockMatches())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+
+	"github.com/augustoccesar/go-ranking/internal/output"
+)
+
+// Size of the charts, in pixels.
+const (
+	chartWidth   = 640
+	chartHeight  = 240
+	chartPadding = 40
+)
+
+// RatingChart draws the rating of the Trajectory as an SVG line, over a band
+// of ± RD. The periods the Team played are marked with a dot.
+func RatingChart(points []*output.TrajectoryPoint) template.HTML {
+	if len(points) == 0 {
+		return ""
+	}
+
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, point := range points {
+		low = math.Min(low, point.Rating-point.RD)
+		high = math.Max(high, point.Rating+point.RD)
+	}
+	if high == low {
+		high++
+	}
+
+	x := func(i int) float64 {
+		if len(points) == 1 {
+			return chartWidth / 2
+		}
+		return chartPadding + float64(i)*float64(chartWidth-2*chartPadding)/float64(len(points)-1)
+	}
+	y := func(value float64) float64 {
+		return chartPadding + (high-value)/(high-low)*float64(chartHeight-2*chartPadding)
+	}
+
+	upper, lower, line := []string{}, []string{}, []string{}
+	dots := &strings.Builder{}
+	for i, point := range points {
+		upper = append(upper, fmt.Sprintf("%.1f,%.1f", x(i), y(point.Rating+point.RD)))
+		lower = append([]string{fmt.Sprintf("%.1f,%.1f", x(i), y(point.Rating-point.RD))}, lower...)
+		line = append(line, fmt.Sprintf("%.1f,%.1f", x(i), y(point.Rating)))
+		if point.Played {
+			fmt.Fprintf(dots, `<circle cx="%.1f" cy="%.1f" r="3"><title>Period %d: %.0f ± %.0f</title></circle>`,
+				x(i), y(point.Rating), point.Period, point.Rating, point.RD)
+		}
+	}
+
+	svg := &strings.Builder{}
+	fmt.Fprintf(svg, `<svg xmlns="http://www.w3.org/2000/svg" class="chart" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(svg, `<polygon class="band" points="%s %s"/>`, strings.Join(upper, " "), strings.Join(lower, " "))
+	fmt.Fprintf(svg, `<polyline class="line" points="%s"/>`, strings.Join(line, " "))
+	svg.WriteString(dots.String())
+	fmt.Fprintf(svg, `<text x="4" y="%d">%.0f</text>`, chartPadding, high)
+	fmt.Fprintf(svg, `<text x="4" y="%d">%.0f</text>`, chartHeight-chartPadding, low)
+	fmt.Fprintf(svg, `<text x="%d" y="%d">%s</text>`, chartPadding, chartHeight-8, points[0].StartDate.Format("2006-01-02"))
+	fmt.Fprintf(svg, `<text x="%d" y="%d" text-anchor="end">%s</text>`, chartWidth-chartPadding, chartHeight-8,
+		points[len(points)-1].EndDate.Format("2006-01-02"))
+	svg.WriteString(`</svg>`)
+
+	// Only numbers and dates are interpolated, so the markup is safe.
+	return template.HTML(svg.String())
+}
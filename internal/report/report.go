@@ -0,0 +1,248 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/history"
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// Movers is the amount of Teams listed on each side of the biggest movers of
+// a period.
+const Movers = 5
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"date": func(at time.Time) string { return at.Format("2006-01-02") },
+	"rows": func(prefix string, rows []*output.Row) map[string]interface{} {
+		return map[string]interface{}{"Prefix": prefix, "Rows": rows}
+	},
+	"movers": moverLines,
+	"delta":  func(point *output.TrajectoryPoint) float64 { return point.Rating - point.PreRating },
+	"result": func(score float64) string {
+		switch score {
+		case 1:
+			return "W"
+		case 0.5:
+			return "T"
+		}
+		return "L"
+	},
+}).ParseFS(templateFiles, "templates/*.html"))
+
+// Report is the struct that holds everything rendered on the site.
+type Report struct {
+	Title       string
+	GeneratedAt time.Time
+	Periods     []*PeriodPage // Oldest first.
+	Teams       []*TeamPage   // By name.
+}
+
+// PeriodPage is the struct that holds the page of a period.
+type PeriodPage struct {
+	ID        int
+	StartDate time.Time
+	EndDate   time.Time
+	Rows      []*output.Row // Ranked by the Leaderboard.
+	Risers    []*output.Row
+	Fallers   []*output.Row
+	Matches   []*MatchLine
+	Previous  int // Id of the previous period, 0 for none.
+	Next      int // Id of the next period, 0 for none.
+}
+
+// MatchLine is the struct that holds a Match of a period.
+type MatchLine struct {
+	ID       int
+	HomeID   int
+	Home     string
+	AwayID   int
+	Away     string
+	WinnerID int // -1 for a tie.
+}
+
+// TeamPage is the struct that holds the page of a Team.
+type TeamPage struct {
+	ID     int
+	Name   string
+	Report *output.TeamReport
+	Chart  template.HTML
+}
+
+// Build builds the Report of calculated RatingPeriods. Each period is ranked
+// by the Leaderboard, with the totals of the Standings (which must be empty).
+func Build(title string, periods []*glicko.RatingPeriod, teams map[int]*ranking.Team,
+	standings *output.Standings, leaderboard *output.Leaderboard) *Report {
+	sorted := make([]*glicko.RatingPeriod, len(periods))
+	copy(sorted, periods)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].EndDate.Before(sorted[j].EndDate)
+	})
+
+	report := &Report{
+		Title:       title,
+		GeneratedAt: time.Now().UTC(),
+		Periods:     []*PeriodPage{},
+		Teams:       []*TeamPage{},
+	}
+
+	for i, ratingPeriod := range sorted {
+		rows := standings.Add(ratingPeriod, teams)
+		page := &PeriodPage{
+			ID:        ratingPeriod.ID,
+			StartDate: ratingPeriod.StartDate,
+			EndDate:   ratingPeriod.EndDate,
+			Matches:   matchLines(ratingPeriod, teams),
+		}
+		page.Risers, page.Fallers = movers(rows)
+		page.Rows = leaderboard.Apply(rows)
+		if i > 0 {
+			page.Previous = sorted[i-1].ID
+		}
+		if i < len(sorted)-1 {
+			page.Next = sorted[i+1].ID
+		}
+		report.Periods = append(report.Periods, page)
+	}
+
+	for teamID, points := range history.BuildHistory(sorted).Trajectories() {
+		teamReport := output.BuildTeamReport(teamID, points, teams)
+		report.Teams = append(report.Teams, &TeamPage{
+			ID:     teamID,
+			Name:   teamReport.Team,
+			Report: teamReport,
+			Chart:  RatingChart(teamReport.Points),
+		})
+	}
+	sort.SliceStable(report.Teams, func(i, j int) bool {
+		if report.Teams[i].Name != report.Teams[j].Name {
+			return report.Teams[i].Name < report.Teams[j].Name
+		}
+		return report.Teams[i].ID < report.Teams[j].ID
+	})
+
+	return report
+}
+
+// Latest returns the page of the last period, or nil without periods.
+func (r *Report) Latest() *PeriodPage {
+	if len(r.Periods) == 0 {
+		return nil
+	}
+	return r.Periods[len(r.Periods)-1]
+}
+
+// Write renders the site on the directory: index.html, a page for each period
+// on periods/ and a page for each Team on teams/.
+func (r *Report) Write(dir string) error {
+	for _, subdir := range []string{"periods", "teams"} {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), 0755); err != nil {
+			return err
+		}
+	}
+
+	if err := render(filepath.Join(dir, "index.html"), "index.html", r); err != nil {
+		return err
+	}
+	for _, page := range r.Periods {
+		path := filepath.Join(dir, "periods", fmt.Sprintf("%d.html", page.ID))
+		if err := render(path, "period.html", map[string]interface{}{"Report": r, "Page": page}); err != nil {
+			return err
+		}
+	}
+	for _, page := range r.Teams {
+		path := filepath.Join(dir, "teams", fmt.Sprintf("%d.html", page.ID))
+		if err := render(path, "team.html", map[string]interface{}{"Report": r, "Page": page}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func render(path, name string, data interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := templates.ExecuteTemplate(file, name, data); err != nil {
+		return fmt.Errorf("report: rendering %s: %v", path, err)
+	}
+	return file.Close()
+}
+
+// movers returns the Rows with the biggest positive and negative deltas.
+func movers(rows []*output.Row) (risers, fallers []*output.Row) {
+	sorted := make([]*output.Row, len(rows))
+	copy(sorted, rows)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Delta > sorted[j].Delta
+	})
+
+	for _, row := range sorted {
+		if row.Delta > 0 && len(risers) < Movers {
+			risers = append(risers, row)
+		}
+	}
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if sorted[i].Delta < 0 && len(fallers) < Movers {
+			fallers = append(fallers, sorted[i])
+		}
+	}
+	return risers, fallers
+}
+
+// moverLine is a line of the table of biggest movers, either side may be nil.
+type moverLine struct {
+	Riser  *output.Row
+	Faller *output.Row
+}
+
+func moverLines(risers, fallers []*output.Row) []*moverLine {
+	lines := []*moverLine{}
+	for i := 0; i < len(risers) || i < len(fallers); i++ {
+		line := &moverLine{}
+		if i < len(risers) {
+			line.Riser = risers[i]
+		}
+		if i < len(fallers) {
+			line.Faller = fallers[i]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func matchLines(ratingPeriod *glicko.RatingPeriod, teams map[int]*ranking.Team) []*MatchLine {
+	lines := []*MatchLine{}
+	for _, match := range ratingPeriod.Matches {
+		lines = append(lines, &MatchLine{
+			ID:       match.ID,
+			HomeID:   match.Home.ID,
+			Home:     teamName(teams, match.Home.ID),
+			AwayID:   match.Away.ID,
+			Away:     teamName(teams, match.Away.ID),
+			WinnerID: match.Winner,
+		})
+	}
+	return lines
+}
+
+func teamName(teams map[int]*ranking.Team, id int) string {
+	if team, ok := teams[id]; ok {
+		return team.Name
+	}
+	return fmt.Sprintf("#%d", id)
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/output"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockPeriods() []*glicko.RatingPeriod {
+	day := func(day int) time.Time { return time.Date(2019, 3, day, 0, 0, 0, 0, time.UTC) }
+	competitors := map[int]*glicko.RankableCompetitor{}
+	for id := 1; id <= 3; id++ {
+		competitors[id] = glicko.BuildRankableCompetitor(id, glicko.BuildDefaultRating())
+	}
+
+	first := glicko.BuildRatingPeriodWithTime(1, day(1), day(8))
+	first.AddNewMatch(competitors[1], competitors[2], 1)
+	first.AddNewMatch(competitors[1], competitors[3], 1)
+	first.Calculate()
+
+	second := glicko.BuildRatingPeriodWithTime(2, day(8).Add(time.Second), day(15))
+	second.AddNewMatch(
+		glicko.BuildRankableCompetitor(1, competitors[1].PostRating),
+		glicko.BuildRankableCompetitor(2, competitors[2].PostRating),
+		2,
+	)
+	second.Calculate()
+
+	// Out of order, Build sorts them.
+	return []*glicko.RatingPeriod{second, first}
+}
+
+func mockTeams() map[int]*ranking.Team {
+	return map[int]*ranking.Team{
+		1: {ID: 1, Name: "Astralis"},
+		2: {ID: 2, Name: "<Liquid>"},
+		3: {ID: 3, Name: "Natus Vincere"},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	report := Build("Ranking", mockPeriods(), mockTeams(), output.BuildStandings(), &output.Leaderboard{})
+
+	assert.Equal(t, 2, len(report.Periods))
+	assert.Equal(t, 1, report.Periods[0].ID)
+	assert.Equal(t, 2, report.Periods[0].Next)
+	assert.Equal(t, 1, report.Latest().Previous)
+	assert.Equal(t, 2, len(report.Periods[0].Matches))
+
+	// 1 won both Matches of the first period, 2 and 3 lost.
+	assert.Equal(t, 1, len(report.Periods[0].Risers))
+	assert.Equal(t, 1, report.Periods[0].Risers[0].TeamID)
+	assert.Equal(t, 2, len(report.Periods[0].Fallers))
+
+	assert.Equal(t, []string{"<Liquid>", "Astralis", "Natus Vincere"},
+		[]string{report.Teams[0].Name, report.Teams[1].Name, report.Teams[2].Name})
+	assert.Equal(t, 2, len(report.Teams[2].Report.Points))
+	assert.Contains(t, string(report.Teams[2].Chart), "<svg")
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	report := Build("Ranking", mockPeriods(), mockTeams(), output.BuildStandings(), &output.Leaderboard{})
+	assert.Nil(t, report.Write(dir))
+
+	for _, path := range []string{"index.html", "periods/1.html", "periods/2.html", "teams/1.html", "teams/2.html", "teams/3.html"} {
+		content, err := ioutil.ReadFile(filepath.Join(dir, path))
+		assert.Nil(t, err, path)
+
+		html := string(content)
+		assert.Contains(t, html, "</html>", path)
+		// Self-contained: no external scripts, styles or images.
+		assert.NotContains(t, html, `src="http`, path)
+		assert.NotContains(t, html, `href="http`, path)
+		assert.False(t, strings.Contains(html, "<Liquid>"), path)
+	}
+
+	team, _ := ioutil.ReadFile(filepath.Join(dir, "teams", "2.html"))
+	assert.Contains(t, string(team), "<svg")
+	assert.Contains(t, string(team), "&lt;Liquid&gt;")
+
+	index, _ := ioutil.ReadFile(filepath.Join(dir, "index.html"))
+	assert.Contains(t, string(index), `href="periods/2.html"`)
+	assert.Contains(t, string(index), `href="teams/1.html"`)
+}
+
+func TestRatingChart(t *testing.T) {
+	assert.Equal(t, "", string(RatingChart(nil)))
+
+	chart := string(RatingChart([]*output.TrajectoryPoint{
+		{Period: 1, Played: true, Rating: 1600, RD: 200},
+		{Period: 2, Played: false, Rating: 1600, RD: 210},
+	}))
+	assert.Contains(t, chart, `<polygon class="band"`)
+	assert.Contains(t, chart, `<polyline class="line"`)
+	// Only the played period has a dot.
+	assert.Equal(t, 1, strings.Count(chart, "<circle"))
+	assert.Contains(t, chart, ">1810<")
+	assert.Contains(t, chart, ">1390<")
+}
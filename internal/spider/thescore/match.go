@@ -6,6 +6,12 @@ import (
 	"time"
 )
 
+// Status values of the Matches on TheScore API.
+const (
+	StatusScheduled = "pre-match"
+	StatusFinished  = "post-match"
+)
+
 // Match is the struct that represents TheScore API response for Match (with
 // stripped down fields for only what I need).
 type Match struct {
@@ -25,21 +31,29 @@ type Match struct {
 }
 
 // extractTeamsIds uses regex to extract the Teams ids from the fields that
-// consists of urls to the Teams
+// consists of urls to the Teams. Teams not defined yet (e.g. fixtures waiting
+// for the result of another Match) get the id 0.
 func (m *Match) extractTeamsIds() (homeID, awayID, winnerID int) {
-	regex := *regexp.MustCompile(`\/csgo\/teams\/(\d+)`)
-
-	homeResult := regex.FindStringSubmatch(m.HomeURL)
-	awayResult := regex.FindStringSubmatch(m.AwayURL)
-	winnerResult := regex.FindStringSubmatch(m.WinnerURL)
-
-	homeID, _ = strconv.Atoi(homeResult[1])
-	awayID, _ = strconv.Atoi(awayResult[1])
-	if m.TieMatch {
+	homeID = extractTeamID(m.HomeURL)
+	awayID = extractTeamID(m.AwayURL)
+	if m.TieMatch || m.WinnerURL == "" {
+		// Scheduled Matches don't have a winner yet.
 		winnerID = -1
 	} else {
-		winnerID, _ = strconv.Atoi(winnerResult[1])
+		winnerID = extractTeamID(m.WinnerURL)
 	}
 
 	return homeID, awayID, winnerID
 }
+
+var teamURLRegex = regexp.MustCompile(`\/csgo\/teams\/(\d+)`)
+
+func extractTeamID(url string) int {
+	result := teamURLRegex.FindStringSubmatch(url)
+	if result == nil {
+		return 0
+	}
+
+	id, _ := strconv.Atoi(result[1])
+	return id
+}
@@ -27,7 +27,27 @@ func BuildPeriodData(
 		Teams:     teams,
 	}
 
-	periodData.purgeBadMatches()
+	periodData.purgeBadMatches(StatusFinished)
+	periodData.populateCache()
+	periodData.assignTeamsToMatches()
+
+	return periodData
+}
+
+// BuildFixturesData is the equivalent of BuildPeriodData for the Matches that
+// didn't start yet.
+func BuildFixturesData(
+	startTime time.Time, endTime time.Time,
+	matches []*Match, teams []*Team,
+) *PeriodData {
+	periodData := &PeriodData{
+		StartTime: startTime,
+		EndTime:   endTime,
+		Matches:   matches,
+		Teams:     teams,
+	}
+
+	periodData.purgeBadMatches(StatusScheduled)
 	periodData.populateCache()
 	periodData.assignTeamsToMatches()
 
@@ -64,10 +84,11 @@ func (pd *PeriodData) assignTeamsToMatches() {
 	}
 }
 
-func (pd *PeriodData) purgeBadMatches() {
+// purgeBadMatches keeps only the Matches with the given status.
+func (pd *PeriodData) purgeBadMatches(status string) {
 	goodMatches := []*Match{}
 	for _, match := range pd.Matches {
-		if match.Status == "post-match" {
+		if match.Status == status {
 			goodMatches = append(goodMatches, match)
 		}
 	}
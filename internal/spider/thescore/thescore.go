@@ -8,14 +8,40 @@ import (
 	"time"
 )
 
+// BaseURL is the root of TheScore API. It is a variable so tests can point it
+// to a fake server.
+var BaseURL = "https://esports-api.thescore.com"
+
 // FetchPeriodData is used to get the PeriodData by a start and end time.
 func FetchPeriodData(startTime, endTime time.Time) (*PeriodData, error) {
+	matches, teams, err := fetchMatches(startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildPeriodData(startTime, endTime, matches, teams), nil
+}
+
+// FetchFixtures is used to get the scheduled Matches (not started yet) between
+// a start and end time, with the Teams already assigned.
+func FetchFixtures(startTime, endTime time.Time) ([]*Match, error) {
+	matches, teams, err := fetchMatches(startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildFixturesData(startTime, endTime, matches, teams).Matches, nil
+}
+
+// fetchMatches does the request to the API and parses the Matches and Teams
+// from the response.
+func fetchMatches(startTime, endTime time.Time) ([]*Match, []*Team, error) {
 	// Parse the dates to the format expected by the API
 	formatedStartTime := startTime.Format(time.RFC3339)
 	formatedEndTime := endTime.Format(time.RFC3339)
 
 	// Build the URL
-	baseURL := "https://esports-api.thescore.com/csgo/matches?%s"
+	baseURL := BaseURL + "/csgo/matches?%s"
 	filter := fmt.Sprintf("start_date_from=%s&start_date_to=%s",
 		formatedStartTime,
 		formatedEndTime,
@@ -25,20 +51,36 @@ func FetchPeriodData(startTime, endTime time.Time) (*PeriodData, error) {
 	// Handle possible error while getting the response
 	resp, err := http.Get(url)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer resp.Body.Close()
-	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("thescore: unexpected status %d", resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Parse the JSON response
 	var rootData map[string]*json.RawMessage
 	var teams []*Team
 	var matches []*Match
 
-	json.Unmarshal(body, &rootData)
-	json.Unmarshal(*rootData["teams"], &teams)
-	json.Unmarshal(*rootData["matches"], &matches)
+	if err := json.Unmarshal(body, &rootData); err != nil {
+		return nil, nil, fmt.Errorf("thescore: invalid response: %v", err)
+	}
+	if rootData["teams"] == nil || rootData["matches"] == nil {
+		return nil, nil, fmt.Errorf("thescore: unexpected response (status %d)", resp.StatusCode)
+	}
 
-	return BuildPeriodData(startTime, endTime, matches, teams), nil
+	if err := json.Unmarshal(*rootData["teams"], &teams); err != nil {
+		return nil, nil, fmt.Errorf("thescore: invalid teams: %v", err)
+	}
+	if err := json.Unmarshal(*rootData["matches"], &matches); err != nil {
+		return nil, nil, fmt.Errorf("thescore: invalid matches: %v", err)
+	}
+
+	return matches, teams, nil
 }
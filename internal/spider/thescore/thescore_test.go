@@ -2,6 +2,8 @@ package thescore
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -22,3 +24,54 @@ func TestFetchPeriodData(t *testing.T) {
 
 	fmt.Print(periodData)
 }
+
+func TestFetchFixtures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"teams": [
+				{"id": 1, "full_name": "Astralis"},
+				{"id": 2, "full_name": "Liquid"}
+			],
+			"matches": [
+				{"id": 10, "status": "pre-match", "team1_url": "/csgo/teams/1", "team2_url": "/csgo/teams/2", "start_date": "2019-03-03T15:00:00Z"},
+				{"id": 11, "status": "pre-match", "team1_url": "", "team2_url": "/csgo/teams/2", "start_date": "2019-03-04T15:00:00Z"},
+				{"id": 12, "status": "post-match", "team1_url": "/csgo/teams/1", "team2_url": "/csgo/teams/2", "winning_team_url": "/csgo/teams/1", "start_date": "2019-03-02T15:00:00Z"}
+			]
+		}`)
+	}))
+	defer server.Close()
+
+	defaultBaseURL := BaseURL
+	BaseURL = server.URL
+	defer func() { BaseURL = defaultBaseURL }()
+
+	fixtures, err := FetchFixtures(time.Now(), time.Now().AddDate(0, 0, 7))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(fixtures))
+	assert.Equal(t, "Astralis", fixtures[0].Home.Name)
+	assert.Equal(t, "Liquid", fixtures[0].Away.Name)
+	assert.Nil(t, fixtures[0].Winner)
+	assert.Nil(t, fixtures[1].Home)
+}
+
+func TestFetchPeriodDataErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("start_date_from") == "2019-03-01T00:00:00Z" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"teams": [], "matches": {}}`)
+	}))
+	defer server.Close()
+
+	defaultBaseURL := BaseURL
+	BaseURL = server.URL
+	defer func() { BaseURL = defaultBaseURL }()
+
+	_, err := FetchPeriodData(time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC))
+	assert.EqualError(t, err, "thescore: unexpected status 503")
+
+	_, err = FetchPeriodData(time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2019, 3, 3, 0, 0, 0, 0, time.UTC))
+	assert.NotNil(t, err)
+}
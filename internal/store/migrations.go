@@ -0,0 +1,152 @@
+package store
+
+import "database/sql"
+
+// migrations holds the schema changes of the database, in order. Once
+// released a migration must never change, new changes go to the end of the
+// list.
+var migrations = []string{
+	// 1: Initial schema.
+	`CREATE TABLE teams (
+		id   INTEGER PRIMARY KEY,
+		name TEXT NOT NULL
+	);
+	CREATE TABLE matches (
+		id         INTEGER PRIMARY KEY,
+		home_id    INTEGER NOT NULL REFERENCES teams(id),
+		away_id    INTEGER NOT NULL REFERENCES teams(id),
+		winner_id  INTEGER REFERENCES teams(id),
+		home_score INTEGER NOT NULL DEFAULT 0,
+		away_score INTEGER NOT NULL DEFAULT 0,
+		start_time TIMESTAMP NOT NULL
+	);
+	CREATE INDEX matches_start_time ON matches(start_time);
+	CREATE TABLE rating_periods (
+		id              INTEGER PRIMARY KEY,
+		start_date      TIMESTAMP NOT NULL,
+		end_date        TIMESTAMP NOT NULL,
+		system_constant REAL NOT NULL
+	);
+	CREATE TABLE ratings (
+		period_id             INTEGER NOT NULL REFERENCES rating_periods(id) ON DELETE CASCADE,
+		competitor_id         INTEGER NOT NULL,
+		position              INTEGER NOT NULL,
+		pre_rating            REAL NOT NULL,
+		pre_rating_derivation REAL NOT NULL,
+		pre_volatility        REAL NOT NULL,
+		post_rating           REAL,
+		post_rating_derivation REAL,
+		post_volatility       REAL,
+		PRIMARY KEY (period_id, competitor_id)
+	);
+	CREATE TABLE period_matches (
+		period_id INTEGER NOT NULL REFERENCES rating_periods(id) ON DELETE CASCADE,
+		position  INTEGER NOT NULL,
+		home_id   INTEGER NOT NULL,
+		away_id   INTEGER NOT NULL,
+		winner_id INTEGER NOT NULL,
+		PRIMARY KEY (period_id, position)
+	);`,
+	// 2: Link the Matches of the periods to the ingested Matches, so the
+	// same Match can never be rated twice.
+	`ALTER TABLE period_matches ADD COLUMN match_id INTEGER;
+	CREATE UNIQUE INDEX period_matches_match_id ON period_matches(match_id);`,
+	// 3: Versions of the ratings. Every recompute writes its periods on a new
+	// version, the existing data becomes the version 1.
+	`CREATE TABLE versions (
+		id             INTEGER PRIMARY KEY,
+		created_at     TIMESTAMP NOT NULL,
+		recompute_from TIMESTAMP,
+		description    TEXT NOT NULL DEFAULT ''
+	);
+	INSERT INTO versions (id, created_at, description) VALUES (1, CURRENT_TIMESTAMP, 'Initial version');
+
+	CREATE TABLE versioned_rating_periods (
+		version_id      INTEGER NOT NULL REFERENCES versions(id) ON DELETE CASCADE,
+		id              INTEGER NOT NULL,
+		start_date      TIMESTAMP NOT NULL,
+		end_date        TIMESTAMP NOT NULL,
+		system_constant REAL NOT NULL,
+		PRIMARY KEY (version_id, id)
+	);
+	CREATE TABLE versioned_ratings (
+		version_id             INTEGER NOT NULL,
+		period_id              INTEGER NOT NULL,
+		competitor_id          INTEGER NOT NULL,
+		position               INTEGER NOT NULL,
+		pre_rating             REAL NOT NULL,
+		pre_rating_derivation  REAL NOT NULL,
+		pre_volatility         REAL NOT NULL,
+		post_rating            REAL,
+		post_rating_derivation REAL,
+		post_volatility        REAL,
+		PRIMARY KEY (version_id, period_id, competitor_id),
+		FOREIGN KEY (version_id, period_id) REFERENCES versioned_rating_periods(version_id, id) ON DELETE CASCADE
+	);
+	CREATE TABLE versioned_period_matches (
+		version_id INTEGER NOT NULL,
+		period_id  INTEGER NOT NULL,
+		position   INTEGER NOT NULL,
+		match_id   INTEGER,
+		home_id    INTEGER NOT NULL,
+		away_id    INTEGER NOT NULL,
+		winner_id  INTEGER NOT NULL,
+		PRIMARY KEY (version_id, period_id, position),
+		FOREIGN KEY (version_id, period_id) REFERENCES versioned_rating_periods(version_id, id) ON DELETE CASCADE
+	);
+
+	INSERT INTO versioned_rating_periods SELECT 1, id, start_date, end_date, system_constant FROM rating_periods;
+	INSERT INTO versioned_ratings SELECT 1, period_id, competitor_id, position,
+		pre_rating, pre_rating_derivation, pre_volatility,
+		post_rating, post_rating_derivation, post_volatility
+		FROM ratings;
+	INSERT INTO versioned_period_matches SELECT 1, period_id, position, match_id, home_id, away_id, winner_id
+		FROM period_matches;
+
+	DROP TABLE period_matches;
+	DROP TABLE ratings;
+	DROP TABLE rating_periods;
+	ALTER TABLE versioned_rating_periods RENAME TO rating_periods;
+	ALTER TABLE versioned_ratings RENAME TO ratings;
+	ALTER TABLE versioned_period_matches RENAME TO period_matches;
+	CREATE UNIQUE INDEX period_matches_match_id ON period_matches(version_id, match_id);`,
+	// 4: Voided Matches are kept, so a source returning them again doesn't
+	// bring them back, but are never rated.
+	`ALTER TABLE matches ADD COLUMN voided INTEGER NOT NULL DEFAULT 0;`,
+}
+
+// migrate applies the migrations that weren't applied yet on the database.
+// Each one runs on its own transaction together with the bump of the schema
+// version.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return err
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+
+	for version := current + 1; version <= len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(migrations[version-1]); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, version); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
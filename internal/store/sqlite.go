@@ -0,0 +1,694 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+
+	// Pure Go SQLite driver, registered as "sqlite".
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore is the Store implementation backed by an embedded SQLite
+// database.
+type SQLiteStore struct {
+	db      *sql.DB
+	version int
+}
+
+var _ Store = &SQLiteStore{}
+
+// OpenSQLite opens (creating if needed) the SQLite database on the path and
+// applies the pending migrations. Use ":memory:" for a throwaway database.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite only supports one writer and each connection to ":memory:" would
+	// be a different database.
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	sqliteStore := &SQLiteStore{db: db}
+	if err := db.QueryRow(`SELECT MAX(id) FROM versions`).Scan(&sqliteStore.version); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return sqliteStore, nil
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// SaveTeams inserts or updates the Teams.
+func (s *SQLiteStore) SaveTeams(teams []*ranking.Team) error {
+	return s.transaction(func(tx *sql.Tx) error {
+		return saveTeams(tx, teams)
+	})
+}
+
+// Teams returns all the Teams known, by id.
+func (s *SQLiteStore) Teams() (map[int]*ranking.Team, error) {
+	rows, err := s.db.Query(`SELECT id, name FROM teams`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := map[int]*ranking.Team{}
+	for rows.Next() {
+		team := &ranking.Team{}
+		if err := rows.Scan(&team.ID, &team.Name); err != nil {
+			return nil, err
+		}
+		teams[team.ID] = team
+	}
+
+	return teams, rows.Err()
+}
+
+// SaveMatches inserts or updates the Matches and their Teams.
+func (s *SQLiteStore) SaveMatches(matches []*ranking.Match) error {
+	return s.transaction(func(tx *sql.Tx) error {
+		teams := []*ranking.Team{}
+		for _, match := range matches {
+			teams = append(teams, match.Home, match.Away)
+		}
+		if err := saveTeams(tx, teams); err != nil {
+			return err
+		}
+
+		for _, match := range matches {
+			if err := saveMatch(tx, match); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Matches returns the Matches that started between the dates, ordered by
+// start time.
+func (s *SQLiteStore) Matches(startDate, endDate time.Time) ([]*ranking.Match, error) {
+	teams, err := s.Teams()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, home_id, away_id, winner_id, home_score, away_score, start_time
+		FROM matches
+		WHERE start_time >= ? AND start_time <= ? AND voided = 0
+		ORDER BY start_time, id`,
+		startDate.UTC(), endDate.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []*ranking.Match{}
+	for rows.Next() {
+		var homeID, awayID int
+		var winnerID sql.NullInt64
+		match := &ranking.Match{}
+
+		err := rows.Scan(&match.ID, &homeID, &awayID, &winnerID, &match.HomeScore, &match.AwayScore, &match.StartTime)
+		if err != nil {
+			return nil, err
+		}
+
+		match.Home = teams[homeID]
+		match.Away = teams[awayID]
+		if winnerID.Valid {
+			match.Winner = teams[int(winnerID.Int64)]
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
+
+// Match returns a Match by its id, or nil when there is none or it was voided.
+func (s *SQLiteStore) Match(id int) (*ranking.Match, error) {
+	teams, err := s.Teams()
+	if err != nil {
+		return nil, err
+	}
+
+	var homeID, awayID int
+	var winnerID sql.NullInt64
+	match := &ranking.Match{ID: id}
+	err = s.db.QueryRow(`
+		SELECT home_id, away_id, winner_id, home_score, away_score, start_time
+		FROM matches
+		WHERE id = ? AND voided = 0`,
+		id,
+	).Scan(&homeID, &awayID, &winnerID, &match.HomeScore, &match.AwayScore, &match.StartTime)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	match.Home = teams[homeID]
+	match.Away = teams[awayID]
+	if winnerID.Valid {
+		match.Winner = teams[int(winnerID.Int64)]
+	}
+	return match, nil
+}
+
+// CreateTeam stores a new Team entered by hand.
+func (s *SQLiteStore) CreateTeam(name string) (*ranking.Team, error) {
+	team := &ranking.Team{Name: name}
+	err := s.transaction(func(tx *sql.Tx) error {
+		var err error
+		if team.ID, err = nextManualID(tx, "teams"); err != nil {
+			return err
+		}
+		return saveTeams(tx, []*ranking.Team{team})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// CreateMatch stores a new Match entered by hand, setting its id.
+func (s *SQLiteStore) CreateMatch(match *ranking.Match) error {
+	return s.transaction(func(tx *sql.Tx) error {
+		id, err := nextManualID(tx, "matches")
+		if err != nil {
+			return err
+		}
+		match.ID = id
+		return saveMatch(tx, match)
+	})
+}
+
+// VoidMatch marks a Match as voided.
+func (s *SQLiteStore) VoidMatch(id int) error {
+	result, err := s.db.Exec(`UPDATE matches SET voided = 1 WHERE id = ? AND voided = 0`, id)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return fmt.Errorf("store: match %d not found", id)
+	}
+	return nil
+}
+
+// SavePeriod stores a calculated RatingPeriod with the Rating snapshots of its
+// Competitors, replacing any period stored with the same id and dates.
+func (s *SQLiteStore) SavePeriod(ratingPeriod *glicko.RatingPeriod) error {
+	return s.transaction(func(tx *sql.Tx) error {
+		if err := s.checkPeriodDates(tx, ratingPeriod); err != nil {
+			return err
+		}
+
+		// The ratings and matches of the period are removed by the cascade.
+		_, err := tx.Exec(`DELETE FROM rating_periods WHERE version_id = ? AND id = ?`, s.version, ratingPeriod.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO rating_periods (version_id, id, start_date, end_date, system_constant)
+			VALUES (?, ?, ?, ?, ?)`,
+			s.version, ratingPeriod.ID, ratingPeriod.StartDate.UTC(), ratingPeriod.EndDate.UTC(), ratingPeriod.SystemConstant,
+		)
+		if err != nil {
+			return err
+		}
+
+		for position, competitor := range ratingPeriod.Competitors {
+			var postRating, postRatingDerivation, postVolatility sql.NullFloat64
+			if competitor.PostRating != nil {
+				postRating = sql.NullFloat64{Float64: competitor.PostRating.Rating, Valid: true}
+				postRatingDerivation = sql.NullFloat64{Float64: competitor.PostRating.RatingDerivation, Valid: true}
+				postVolatility = sql.NullFloat64{Float64: competitor.PostRating.Volatility, Valid: true}
+			}
+
+			_, err := tx.Exec(`
+				INSERT INTO ratings (
+					version_id, period_id, competitor_id, position,
+					pre_rating, pre_rating_derivation, pre_volatility,
+					post_rating, post_rating_derivation, post_volatility
+				) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				s.version, ratingPeriod.ID, competitor.ID, position,
+				competitor.PreRating.Rating, competitor.PreRating.RatingDerivation, competitor.PreRating.Volatility,
+				postRating, postRatingDerivation, postVolatility,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		for position, match := range ratingPeriod.Matches {
+			var matchID sql.NullInt64
+			if match.ID != 0 {
+				matchID = sql.NullInt64{Int64: int64(match.ID), Valid: true}
+			}
+
+			_, err := tx.Exec(`
+				INSERT INTO period_matches (version_id, period_id, position, match_id, home_id, away_id, winner_id)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				s.version, ratingPeriod.ID, position, matchID, match.Home.ID, match.Away.ID, match.Winner,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Periods returns all the stored RatingPeriods ordered by id.
+func (s *SQLiteStore) Periods() ([]*glicko.RatingPeriod, error) {
+	rows, err := s.db.Query(`
+		SELECT id, start_date, end_date, system_constant FROM rating_periods
+		WHERE version_id = ? ORDER BY id`,
+		s.version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := []*glicko.RatingPeriod{}
+	for rows.Next() {
+		ratingPeriod := glicko.BuildRatingPeriod(0)
+		if err := rows.Scan(&ratingPeriod.ID, &ratingPeriod.StartDate, &ratingPeriod.EndDate, &ratingPeriod.SystemConstant); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		periods = append(periods, ratingPeriod)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, ratingPeriod := range periods {
+		if err := s.loadPeriodContent(ratingPeriod); err != nil {
+			return nil, err
+		}
+	}
+
+	return periods, nil
+}
+
+// LastPeriod returns the stored RatingPeriod with the highest id, or nil when
+// there is none.
+func (s *SQLiteStore) LastPeriod() (*glicko.RatingPeriod, error) {
+	ratingPeriod := glicko.BuildRatingPeriod(0)
+	err := s.db.QueryRow(`
+		SELECT id, start_date, end_date, system_constant FROM rating_periods
+		WHERE version_id = ? ORDER BY id DESC LIMIT 1`,
+		s.version,
+	).Scan(&ratingPeriod.ID, &ratingPeriod.StartDate, &ratingPeriod.EndDate, &ratingPeriod.SystemConstant)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadPeriodContent(ratingPeriod); err != nil {
+		return nil, err
+	}
+	return ratingPeriod, nil
+}
+
+// LatestRatings returns, for every Competitor, the PostRating of the last
+// period it played.
+func (s *SQLiteStore) LatestRatings() (map[int]*glicko.Rating, error) {
+	rows, err := s.db.Query(`
+		SELECT r.competitor_id, r.post_rating, r.post_rating_derivation, r.post_volatility
+		FROM ratings r
+		JOIN (
+			SELECT competitor_id, MAX(period_id) AS period_id FROM ratings
+			WHERE version_id = ? AND post_rating IS NOT NULL GROUP BY competitor_id
+		) latest ON latest.competitor_id = r.competitor_id AND latest.period_id = r.period_id
+		WHERE r.version_id = ?`,
+		s.version, s.version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := map[int]*glicko.Rating{}
+	for rows.Next() {
+		var id int
+		var rating, ratingDerivation, volatility float64
+		if err := rows.Scan(&id, &rating, &ratingDerivation, &volatility); err != nil {
+			return nil, err
+		}
+		ratings[id] = glicko.BuildRating(rating, ratingDerivation, volatility)
+	}
+
+	return ratings, rows.Err()
+}
+
+// RatedMatchIDs returns the ids of the Matches already used on a stored
+// period.
+func (s *SQLiteStore) RatedMatchIDs() (map[int]bool, error) {
+	rows, err := s.db.Query(`
+		SELECT match_id FROM period_matches
+		WHERE version_id = ? AND match_id IS NOT NULL`,
+		s.version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
+}
+
+// Version returns the id of the Version in use.
+func (s *SQLiteStore) Version() int {
+	return s.version
+}
+
+// UseVersion changes the Version used by the period methods.
+func (s *SQLiteStore) UseVersion(id int) error {
+	var exists bool
+	if err := s.db.QueryRow(`SELECT COUNT(*) > 0 FROM versions WHERE id = ?`, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("store: version %d not found", id)
+	}
+
+	s.version = id
+	return nil
+}
+
+// Versions lists all the Versions, oldest first.
+func (s *SQLiteStore) Versions() ([]*Version, error) {
+	rows, err := s.db.Query(`SELECT id, created_at, recompute_from, description FROM versions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []*Version{}
+	for rows.Next() {
+		var recomputeFrom sql.NullTime
+		version := &Version{}
+		if err := rows.Scan(&version.ID, &version.CreatedAt, &recomputeFrom, &version.Description); err != nil {
+			return nil, err
+		}
+		version.RecomputeFrom = recomputeFrom.Time
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// CreateVersion creates a Version with a copy of the periods of the current
+// one that ended before `from`, and starts using it.
+func (s *SQLiteStore) CreateVersion(from time.Time, description string) (*Version, error) {
+	version := &Version{
+		CreatedAt:     time.Now().UTC(),
+		RecomputeFrom: from.UTC(),
+		Description:   description,
+	}
+
+	err := s.transaction(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			INSERT INTO versions (created_at, recompute_from, description) VALUES (?, ?, ?)`,
+			version.CreatedAt, version.RecomputeFrom, version.Description,
+		)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		version.ID = int(id)
+
+		copies := []string{
+			`INSERT INTO rating_periods
+				SELECT ?, id, start_date, end_date, system_constant FROM rating_periods
+				WHERE version_id = ? AND end_date < ?`,
+			`INSERT INTO ratings
+				SELECT ?, r.period_id, r.competitor_id, r.position,
+					r.pre_rating, r.pre_rating_derivation, r.pre_volatility,
+					r.post_rating, r.post_rating_derivation, r.post_volatility
+				FROM ratings r JOIN rating_periods p ON p.version_id = r.version_id AND p.id = r.period_id
+				WHERE r.version_id = ? AND p.end_date < ?`,
+			`INSERT INTO period_matches
+				SELECT ?, m.period_id, m.position, m.match_id, m.home_id, m.away_id, m.winner_id
+				FROM period_matches m JOIN rating_periods p ON p.version_id = m.version_id AND p.id = m.period_id
+				WHERE m.version_id = ? AND p.end_date < ?`,
+		}
+		for _, query := range copies {
+			if _, err := tx.Exec(query, version.ID, s.version, from.UTC()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.version = version.ID
+	return version, nil
+}
+
+// DeleteVersion removes a Version with all its periods.
+func (s *SQLiteStore) DeleteVersion(id int) error {
+	if _, err := s.db.Exec(`DELETE FROM versions WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	if s.version == id {
+		return s.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM versions`).Scan(&s.version)
+	}
+	return nil
+}
+
+// checkPeriodDates refuses a RatingPeriod that doesn't line up with the
+// stored ones: one with the id of a stored period but other dates, or one
+// whose dates overlap a stored period with another id. Saving it would
+// replace or duplicate unrelated periods.
+func (s *SQLiteStore) checkPeriodDates(tx *sql.Tx, ratingPeriod *glicko.RatingPeriod) error {
+	rows, err := tx.Query(`SELECT id, start_date, end_date FROM rating_periods WHERE version_id = ?`, s.version)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var startDate, endDate time.Time
+		if err := rows.Scan(&id, &startDate, &endDate); err != nil {
+			return err
+		}
+
+		sameDates := startDate.Equal(ratingPeriod.StartDate) && endDate.Equal(ratingPeriod.EndDate)
+		overlaps := startDate.Before(ratingPeriod.EndDate) && endDate.After(ratingPeriod.StartDate)
+		if (id == ratingPeriod.ID && !sameDates) || (id != ratingPeriod.ID && overlaps) {
+			return fmt.Errorf("store: period %d (%s - %s) doesn't line up with the stored period %d (%s - %s)",
+				ratingPeriod.ID, ratingPeriod.StartDate.UTC().Format(time.RFC3339), ratingPeriod.EndDate.UTC().Format(time.RFC3339),
+				id, startDate.UTC().Format(time.RFC3339), endDate.UTC().Format(time.RFC3339))
+		}
+	}
+	return rows.Err()
+}
+
+// loadPeriodContent loads the Competitors and Matches of a RatingPeriod.
+// The Matches are added with AddBuiltMatch, which registers the Competitors
+// in the same order they were saved. A period saved after Calculate is
+// loaded as calculated, so it takes no new Matches.
+func (s *SQLiteStore) loadPeriodContent(ratingPeriod *glicko.RatingPeriod) error {
+	// Each query is consumed before the next one, since there is only one
+	// connection available.
+	competitors, err := s.loadCompetitors(ratingPeriod.ID)
+	if err != nil {
+		return err
+	}
+
+	competitorsByID := map[int]*glicko.RankableCompetitor{}
+	for _, competitor := range competitors {
+		competitorsByID[competitor.ID] = competitor
+	}
+
+	rows, err := s.db.Query(`
+		SELECT match_id, home_id, away_id, winner_id FROM period_matches
+		WHERE version_id = ? AND period_id = ? ORDER BY position`,
+		s.version, ratingPeriod.ID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var matchID sql.NullInt64
+		var homeID, awayID, winnerID int
+		if err := rows.Scan(&matchID, &homeID, &awayID, &winnerID); err != nil {
+			return err
+		}
+
+		home, homeOk := competitorsByID[homeID]
+		away, awayOk := competitorsByID[awayID]
+		if !homeOk || !awayOk {
+			return fmt.Errorf("store: match %d-%d of period %d references competitors without ratings",
+				homeID, awayID, ratingPeriod.ID)
+		}
+
+		match := glicko.BuildRankableMatch(home, away, winnerID)
+		match.ID = int(matchID.Int64)
+		if err := ratingPeriod.AddBuiltMatch(match); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// A Competitor without Matches isn't registered by AddBuiltMatch, but
+	// its rating was saved, so it is kept.
+	calculated := len(competitors) > 0
+	for _, competitor := range competitors {
+		if ratingPeriod.Competitor(competitor.ID) == nil {
+			ratingPeriod.Competitors = append(ratingPeriod.Competitors, competitor)
+		}
+		calculated = calculated && competitor.PostRating != nil
+	}
+
+	if calculated {
+		ratingPeriod.MarkCalculated()
+	}
+	return nil
+}
+
+// loadCompetitors loads the Competitors of a RatingPeriod with their Rating
+// snapshots.
+func (s *SQLiteStore) loadCompetitors(periodID int) ([]*glicko.RankableCompetitor, error) {
+	rows, err := s.db.Query(`
+		SELECT competitor_id,
+			pre_rating, pre_rating_derivation, pre_volatility,
+			post_rating, post_rating_derivation, post_volatility
+		FROM ratings WHERE version_id = ? AND period_id = ? ORDER BY position`,
+		s.version, periodID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	competitors := []*glicko.RankableCompetitor{}
+	for rows.Next() {
+		var id int
+		var preRating, preRatingDerivation, preVolatility float64
+		var postRating, postRatingDerivation, postVolatility sql.NullFloat64
+
+		err := rows.Scan(&id,
+			&preRating, &preRatingDerivation, &preVolatility,
+			&postRating, &postRatingDerivation, &postVolatility,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		competitor := glicko.BuildRankableCompetitor(id, glicko.BuildRating(preRating, preRatingDerivation, preVolatility))
+		if postRating.Valid {
+			competitor.PostRating = glicko.BuildRating(postRating.Float64, postRatingDerivation.Float64, postVolatility.Float64)
+		}
+		competitors = append(competitors, competitor)
+	}
+
+	return competitors, rows.Err()
+}
+
+// transaction runs the function inside a transaction, committing it if no
+// error is returned and rolling back otherwise.
+func (s *SQLiteStore) transaction(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// saveMatch inserts or updates a Match, keeping it voided if it was.
+func saveMatch(tx *sql.Tx, match *ranking.Match) error {
+	var winnerID sql.NullInt64
+	if match.Winner != nil {
+		winnerID = sql.NullInt64{Int64: int64(match.Winner.ID), Valid: true}
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO matches (id, home_id, away_id, winner_id, home_score, away_score, start_time)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			home_id = excluded.home_id, away_id = excluded.away_id,
+			winner_id = excluded.winner_id, home_score = excluded.home_score,
+			away_score = excluded.away_score, start_time = excluded.start_time`,
+		match.ID, match.Home.ID, match.Away.ID, winnerID,
+		match.HomeScore, match.AwayScore, match.StartTime.UTC(),
+	)
+	return err
+}
+
+// nextManualID returns the next id for a row entered by hand on the table.
+func nextManualID(tx *sql.Tx, table string) (int, error) {
+	var id int
+	err := tx.QueryRow(`SELECT MAX(COALESCE(MAX(id), 0), ?) + 1 FROM `+table+` WHERE id >= ?`,
+		ManualIDStart-1, ManualIDStart).Scan(&id)
+	return id, err
+}
+
+// saveTeams inserts or updates the Teams. An empty name (a Team known only by
+// its id) keeps the stored one.
+func saveTeams(tx *sql.Tx, teams []*ranking.Team) error {
+	for _, team := range teams {
+		_, err := tx.Exec(`
+			INSERT INTO teams (id, name) VALUES (?, ?)
+			ON CONFLICT (id) DO UPDATE SET name = COALESCE(NULLIF(excluded.name, ''), teams.name)`,
+			team.ID, team.Name,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
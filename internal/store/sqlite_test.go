@@ -0,0 +1,239 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockMatches() []*ranking.Match {
+	teamA := &ranking.Team{ID: 1, Name: "A"}
+	teamB := &ranking.Team{ID: 2, Name: "B"}
+
+	return []*ranking.Match{
+		{ID: 10, Home: teamA, Away: teamB, Winner: teamA, HomeScore: 2, AwayScore: 1, StartTime: time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: 11, Home: teamB, Away: teamA, Winner: nil, StartTime: time.Date(2019, 3, 3, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+func mockRatingPeriod() *glicko.RatingPeriod {
+	competitor1 := glicko.BuildRankableCompetitor(1, glicko.BuildRating(1500, 200, 0.06))
+	competitor2 := glicko.BuildRankableCompetitor(2, glicko.BuildRating(1400, 30, 0.06))
+	competitor3 := glicko.BuildRankableCompetitor(3, glicko.BuildRating(1550, 100, 0.06))
+
+	ratingPeriod := glicko.BuildRatingPeriodWithTime(1,
+		time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2019, 3, 8, 0, 0, 0, 0, time.UTC),
+	)
+	ratingPeriod.AddNewMatch(competitor1, competitor2, 1)
+	ratingPeriod.AddNewMatch(competitor1, competitor3, -1)
+	ratingPeriod.Calculate()
+
+	return ratingPeriod
+}
+
+func TestMatches(t *testing.T) {
+	db, err := OpenSQLite(":memory:")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	// Saving twice must not duplicate anything.
+	assert.Nil(t, db.SaveMatches(mockMatches()))
+	assert.Nil(t, db.SaveMatches(mockMatches()))
+
+	matches, err := db.Matches(time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 3, 8, 0, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(matches))
+	assert.Equal(t, 10, matches[0].ID)
+	assert.Equal(t, "A", matches[0].Home.Name)
+	assert.Equal(t, 1, matches[0].WinnerID())
+	assert.Equal(t, 2, matches[0].HomeScore)
+	assert.True(t, matches[0].StartTime.Equal(time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC)))
+	assert.Nil(t, matches[1].Winner)
+
+	matches, _ = db.Matches(time.Date(2019, 3, 3, 0, 0, 0, 0, time.UTC), time.Date(2019, 3, 8, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, 1, len(matches))
+
+	teams, _ := db.Teams()
+	assert.Equal(t, 2, len(teams))
+}
+
+func TestSaveTeamsKeepsStoredName(t *testing.T) {
+	db, _ := OpenSQLite(":memory:")
+	defer db.Close()
+
+	assert.Nil(t, db.SaveTeams([]*ranking.Team{{ID: 1, Name: "Astralis"}}))
+	assert.Nil(t, db.SaveTeams([]*ranking.Team{{ID: 1}}))
+
+	teams, _ := db.Teams()
+	assert.Equal(t, "Astralis", teams[1].Name)
+
+	// A new name still replaces it.
+	assert.Nil(t, db.SaveTeams([]*ranking.Team{{ID: 1, Name: "Astralis Talent"}}))
+	teams, _ = db.Teams()
+	assert.Equal(t, "Astralis Talent", teams[1].Name)
+}
+
+func TestPeriods(t *testing.T) {
+	db, _ := OpenSQLite(":memory:")
+	defer db.Close()
+
+	ratingPeriod := mockRatingPeriod()
+
+	// Saving twice replaces the period.
+	assert.Nil(t, db.SavePeriod(ratingPeriod))
+	assert.Nil(t, db.SavePeriod(ratingPeriod))
+
+	periods, err := db.Periods()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(periods))
+
+	loaded := periods[0]
+	assert.Equal(t, 1, loaded.ID)
+	assert.True(t, loaded.StartDate.Equal(ratingPeriod.StartDate))
+	assert.Equal(t, 3, len(loaded.Competitors))
+	assert.Equal(t, 2, len(loaded.Matches))
+	assert.Equal(t, 2, len(loaded.Competitors[0].Matches))
+	assert.Equal(t, -1, loaded.Matches[1].Winner)
+
+	for i, competitor := range loaded.Competitors {
+		original := ratingPeriod.Competitors[i]
+		assert.Equal(t, original.ID, competitor.ID)
+		assert.Equal(t, original.PreRating.Rating, competitor.PreRating.Rating)
+		assert.Equal(t, original.PostRating.Rating, competitor.PostRating.Rating)
+		assert.Equal(t, original.PostRating.Volatility, competitor.PostRating.Volatility)
+	}
+
+	// The Matches point to the same Competitors of the period.
+	assert.True(t, loaded.Matches[0].Home == loaded.Competitors[0])
+
+	// A calculated period takes no new Matches once loaded.
+	assert.True(t, loaded.Calculated())
+	assert.Equal(t, glicko.ErrCalculated, loaded.AddNewMatch(loaded.Competitors[0], loaded.Competitors[1], 1))
+	assert.Equal(t, 2, len(loaded.Matches))
+}
+
+func TestPeriodsNotCalculated(t *testing.T) {
+	db, _ := OpenSQLite(":memory:")
+	defer db.Close()
+
+	ratingPeriod := glicko.BuildRatingPeriodWithTime(1,
+		time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2019, 3, 8, 0, 0, 0, 0, time.UTC),
+	)
+	ratingPeriod.AddNewMatch(
+		glicko.BuildRankableCompetitor(1, glicko.BuildDefaultRating()),
+		glicko.BuildRankableCompetitor(2, glicko.BuildDefaultRating()),
+		1,
+	)
+	assert.Nil(t, db.SavePeriod(ratingPeriod))
+
+	loaded, err := db.LastPeriod()
+	assert.Nil(t, err)
+	assert.False(t, loaded.Calculated())
+}
+
+func TestSavePeriodRefusesMisalignedPeriods(t *testing.T) {
+	db, _ := OpenSQLite(":memory:")
+	defer db.Close()
+
+	assert.Nil(t, db.SavePeriod(mockRatingPeriod()))
+	stored := mockRatingPeriod()
+
+	// Same id, starting a day later (as a run with another --start_date).
+	shifted := glicko.BuildRatingPeriodWithTime(1, stored.StartDate.AddDate(0, 0, 1), stored.EndDate.AddDate(0, 0, 1))
+	assert.NotNil(t, db.SavePeriod(shifted))
+
+	// Another id over the same dates.
+	duplicated := glicko.BuildRatingPeriodWithTime(2, stored.StartDate, stored.EndDate)
+	assert.NotNil(t, db.SavePeriod(duplicated))
+
+	// The next period is fine.
+	next := glicko.BuildRatingPeriodWithTime(2, stored.EndDate, stored.EndDate.AddDate(0, 0, 7))
+	assert.Nil(t, db.SavePeriod(next))
+
+	periods, _ := db.Periods()
+	assert.Equal(t, 2, len(periods))
+	assert.True(t, periods[0].StartDate.Equal(stored.StartDate))
+	assert.Equal(t, 2, len(periods[0].Matches))
+}
+
+func TestPeriodsWithMissingRating(t *testing.T) {
+	db, _ := OpenSQLite(":memory:")
+	defer db.Close()
+
+	assert.Nil(t, db.SavePeriod(mockRatingPeriod()))
+	_, err := db.db.Exec(`DELETE FROM ratings WHERE competitor_id = 3`)
+	assert.Nil(t, err)
+
+	_, err = db.Periods()
+	assert.NotNil(t, err)
+
+	_, err = db.LastPeriod()
+	assert.NotNil(t, err)
+}
+
+func TestMigrationsAreAppliedOnce(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "store")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ranking.db")
+
+	db, err := OpenSQLite(path)
+	assert.Nil(t, err)
+	assert.Nil(t, db.SaveMatches(mockMatches()))
+	db.Close()
+
+	db, err = OpenSQLite(path)
+	assert.Nil(t, err)
+	defer db.Close()
+
+	var version int
+	db.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+	assert.Equal(t, len(migrations), version)
+
+	matches, _ := db.Matches(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, 2, len(matches))
+}
+
+func TestManualEntries(t *testing.T) {
+	db, err := OpenSQLite(":memory:")
+	assert.Nil(t, err)
+	defer db.Close()
+	assert.Nil(t, db.SaveMatches(mockMatches()))
+
+	home, err := db.CreateTeam("Home")
+	assert.Nil(t, err)
+	assert.Equal(t, ManualIDStart, home.ID)
+	away, _ := db.CreateTeam("Away")
+	assert.Equal(t, ManualIDStart+1, away.ID)
+
+	match := &ranking.Match{Home: home, Away: away, Winner: away, AwayScore: 2, StartTime: time.Date(2019, 3, 4, 0, 0, 0, 0, time.UTC)}
+	assert.Nil(t, db.CreateMatch(match))
+	assert.Equal(t, ManualIDStart, match.ID)
+
+	loaded, err := db.Match(match.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "Away", loaded.Winner.Name)
+	assert.Equal(t, 2, loaded.AwayScore)
+
+	// Voided Matches are gone, even if saved again.
+	assert.Nil(t, db.VoidMatch(10))
+	assert.NotNil(t, db.VoidMatch(10))
+	assert.Nil(t, db.SaveMatches(mockMatches()))
+	loaded, err = db.Match(10)
+	assert.Nil(t, err)
+	assert.Nil(t, loaded)
+
+	matches, _ := db.Matches(time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 3, 8, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, 2, len(matches))
+	assert.Equal(t, 11, matches[0].ID)
+	assert.Equal(t, match.ID, matches[1].ID)
+}
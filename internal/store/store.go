@@ -0,0 +1,87 @@
+package store
+
+import (
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// ManualIDStart is the first id given to the Teams and Matches entered by
+// hand, far above the ids of the sources so they never collide.
+const ManualIDStart = 1 << 30
+
+// Version is the struct that identifies one full set of RatingPeriods. A new
+// one is created every time the history is recomputed, so the previous
+// results are kept for comparison.
+type Version struct {
+	ID            int
+	CreatedAt     time.Time
+	RecomputeFrom time.Time // Zero for the initial version.
+	Description   string
+}
+
+// Store is the interface of the persistence layer. It keeps the Teams and
+// Matches fetched from the sources and the results of the RatingPeriods (the
+// Pre/Post Rating of every Competitor on each period).
+type Store interface {
+	// SaveTeams inserts or updates the Teams.
+	SaveTeams(teams []*ranking.Team) error
+	// Teams returns all the Teams known, by id.
+	Teams() (map[int]*ranking.Team, error)
+
+	// SaveMatches inserts or updates the Matches (and their Teams). Matches
+	// are identified by their id, so saving one already stored overwrites its
+	// Teams, winner, scores and start time. A voided Match stays voided.
+	SaveMatches(matches []*ranking.Match) error
+	// Matches returns the Matches that started between the dates, ordered by
+	// start time. Voided Matches are left out.
+	Matches(startDate, endDate time.Time) ([]*ranking.Match, error)
+	// Match returns a Match by its id, or nil when there is none or it was
+	// voided.
+	Match(id int) (*ranking.Match, error)
+	// CreateTeam stores a new Team entered by hand, with an id from
+	// ManualIDStart on.
+	CreateTeam(name string) (*ranking.Team, error)
+	// CreateMatch stores a new Match entered by hand, setting its id (from
+	// ManualIDStart on).
+	CreateMatch(match *ranking.Match) error
+	// VoidMatch marks a Match as voided, so it is never rated again. Periods
+	// that already rated it must be recomputed.
+	VoidMatch(id int) error
+
+	// SavePeriod stores a calculated RatingPeriod with the Rating snapshots of
+	// its Competitors, replacing any period stored with the same id and dates.
+	// A period with the id of a stored one but other dates, or overlapping a
+	// stored one with another id, is refused. It is done inside a single
+	// transaction.
+	SavePeriod(ratingPeriod *glicko.RatingPeriod) error
+	// Periods returns all the stored RatingPeriods ordered by id, with
+	// Competitors and Matches linked as they were when saved.
+	Periods() ([]*glicko.RatingPeriod, error)
+	// LastPeriod returns the stored RatingPeriod with the highest id, or nil
+	// when there is none. It is the checkpoint from where updates resume.
+	LastPeriod() (*glicko.RatingPeriod, error)
+	// LatestRatings returns, for every Competitor, the PostRating of the last
+	// period it played.
+	LatestRatings() (map[int]*glicko.Rating, error)
+	// RatedMatchIDs returns the ids of the Matches already used on a stored
+	// period.
+	RatedMatchIDs() (map[int]bool, error)
+
+	// Version returns the id of the Version used by the period methods. When
+	// opened, the Store uses the latest one.
+	Version() int
+	// UseVersion changes the Version used by the period methods.
+	UseVersion(id int) error
+	// Versions lists all the Versions, oldest first.
+	Versions() ([]*Version, error)
+	// CreateVersion creates a Version with a copy of the periods of the
+	// current one that ended before `from`, and starts using it.
+	CreateVersion(from time.Time, description string) (*Version, error)
+	// DeleteVersion removes a Version with all its periods. If it is the
+	// one in use, the Store goes back to the latest remaining one.
+	DeleteVersion(id int) error
+
+	Close() error
+}
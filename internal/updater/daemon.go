@@ -0,0 +1,138 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// Run is the struct that holds the outcome of one run of a Daemon.
+type Run struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Periods    int       `json:"periods"` // New periods calculated.
+	Error      string    `json:"error,omitempty"`
+}
+
+// Status is the struct that holds the health of a Daemon.
+type Status struct {
+	Healthy             bool      `json:"healthy"` // False while the last run failed.
+	Running             bool      `json:"running"`
+	StartedAt           time.Time `json:"started_at"`
+	Runs                int       `json:"runs"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastRun             *Run      `json:"last_run,omitempty"`
+	LastSuccess         time.Time `json:"last_success"`
+	NextRun             time.Time `json:"next_run"`
+}
+
+// Daemon is the struct that runs an Updater on a fixed interval. Since the
+// Updater resumes from the periods stored, restarting the Daemon never
+// processes a period twice.
+type Daemon struct {
+	Updater  *Updater
+	Interval time.Duration
+	Now      func() time.Time // Moment passed to Update, time.Now by default.
+
+	// OnRun, when set, is called after each run with the new periods (none
+	// when the run failed).
+	OnRun func(run *Run, periods []*glicko.RatingPeriod)
+
+	mutex  sync.RWMutex
+	status Status
+}
+
+// BuildDaemon builds a Daemon.
+func BuildDaemon(updater *Updater, interval time.Duration) *Daemon {
+	return &Daemon{
+		Updater:  updater,
+		Interval: interval,
+		Now:      func() time.Time { return time.Now().UTC() },
+		status:   Status{Healthy: true},
+	}
+}
+
+// Run runs the Updater right away and then on every Interval, until the
+// context is done. A run in progress is never interrupted: Run returns once
+// it is over.
+func (d *Daemon) Run(ctx context.Context) {
+	d.mutex.Lock()
+	d.status.StartedAt = d.Now()
+	d.mutex.Unlock()
+
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		d.RunOnce()
+
+		d.mutex.Lock()
+		d.status.NextRun = d.Now().Add(d.Interval)
+		d.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs the Updater until the current moment and records the outcome.
+func (d *Daemon) RunOnce() *Run {
+	d.mutex.Lock()
+	d.status.Running = true
+	d.mutex.Unlock()
+
+	run := &Run{StartedAt: d.Now()}
+	periods, err := d.Updater.Update(run.StartedAt)
+	run.FinishedAt = d.Now()
+	run.Periods = len(periods)
+	if err != nil {
+		run.Error = err.Error()
+		periods = nil
+	}
+
+	d.mutex.Lock()
+	d.status.Running = false
+	d.status.Runs++
+	d.status.LastRun = run
+	d.status.Healthy = err == nil
+	if err != nil {
+		d.status.ConsecutiveFailures++
+	} else {
+		d.status.ConsecutiveFailures = 0
+		d.status.LastSuccess = run.FinishedAt
+	}
+	d.mutex.Unlock()
+
+	if d.OnRun != nil {
+		d.OnRun(run, periods)
+	}
+	return run
+}
+
+// Status returns a copy of the current Status.
+func (d *Daemon) Status() Status {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.status
+}
+
+// ServeHTTP writes the Status as JSON, with 503 when the last run failed so
+// it can be used as a health check.
+func (d *Daemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := d.Status()
+
+	w.Header().Set("Content-Type", "application/json")
+	if status.Healthy {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
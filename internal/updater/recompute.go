@@ -0,0 +1,138 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/store"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// Movement is the struct that holds how much the current Rating of a
+// Competitor changed between two Versions. Previous or Current is nil when
+// the Competitor only has a Rating on one of them.
+type Movement struct {
+	CompetitorID int
+	Previous     *glicko.Rating
+	Current      *glicko.Rating
+	Delta        float64
+}
+
+// Recomputation is the struct that holds the result of a Recompute.
+type Recomputation struct {
+	PreviousVersion int
+	Version         *store.Version
+	Periods         []*glicko.RatingPeriod
+	Movements       []*Movement // Biggest movements first.
+}
+
+// Recompute replays the stored Matches from the period that contains `from`
+// until the given moment, with the current configuration of the Updater,
+// writing the result on a new Version. The periods that ended before `from`
+// are copied from the current Version, so only the stale ones are
+// recalculated. Nothing is fetched from the source.
+func (u *Updater) Recompute(from, until time.Time, description string) (*Recomputation, error) {
+	previousVersion := u.Store.Version()
+	previousRatings, err := u.Store.LatestRatings()
+	if err != nil {
+		return nil, err
+	}
+
+	// The replay must keep the same period boundaries of the history.
+	startDate := u.StartDate
+	previousPeriods, err := u.Store.Periods()
+	if err != nil {
+		return nil, err
+	}
+	if len(previousPeriods) > 0 {
+		startDate = previousPeriods[0].StartDate
+	}
+
+	version, err := u.Store.CreateVersion(from, description)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := *u
+	replay.Fetch = nil
+	replay.StartDate = startDate
+
+	periods, err := replay.Update(until)
+	if err != nil {
+		// Don't leave a half calculated Version as the latest one. If that
+		// fails too, the Store may be left on it, so it is reported.
+		if deleteErr := u.Store.DeleteVersion(version.ID); deleteErr != nil {
+			err = errors.Join(err, fmt.Errorf("deleting version %d: %w", version.ID, deleteErr))
+		}
+		if useErr := u.Store.UseVersion(previousVersion); useErr != nil {
+			err = errors.Join(err, fmt.Errorf("going back to version %d: %w", previousVersion, useErr))
+		}
+		return nil, err
+	}
+
+	currentRatings, err := u.Store.LatestRatings()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recomputation{
+		PreviousVersion: previousVersion,
+		Version:         version,
+		Periods:         periods,
+		Movements:       movements(previousRatings, currentRatings),
+	}, nil
+}
+
+// Amend brings the stored periods in line with a Match that started at
+// `changedAt` and was recorded late, edited or voided: when a stored period
+// already covers that moment, the history is recomputed from it until the
+// given moment. Otherwise nothing needs to change, the next Update rates the
+// Match, and nil is returned.
+func (u *Updater) Amend(changedAt, until time.Time, description string) (*Recomputation, error) {
+	lastPeriod, err := u.Store.LastPeriod()
+	if err != nil {
+		return nil, err
+	}
+	if lastPeriod == nil || changedAt.After(lastPeriod.EndDate) {
+		return nil, nil
+	}
+	if until.Before(lastPeriod.EndDate) {
+		until = lastPeriod.EndDate
+	}
+
+	return u.Recompute(changedAt, until, description)
+}
+
+// movements compares the ratings of two Versions.
+func movements(previous, current map[int]*glicko.Rating) []*Movement {
+	byCompetitor := map[int]*Movement{}
+	for id, rating := range previous {
+		byCompetitor[id] = &Movement{CompetitorID: id, Previous: rating}
+	}
+	for id, rating := range current {
+		if _, ok := byCompetitor[id]; !ok {
+			byCompetitor[id] = &Movement{CompetitorID: id}
+		}
+		byCompetitor[id].Current = rating
+	}
+
+	result := []*Movement{}
+	for _, movement := range byCompetitor {
+		if movement.Previous != nil && movement.Current != nil {
+			movement.Delta = movement.Current.Rating - movement.Previous.Rating
+		}
+		result = append(result, movement)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if math.Abs(result[i].Delta) != math.Abs(result[j].Delta) {
+			return math.Abs(result[i].Delta) > math.Abs(result[j].Delta)
+		}
+		return result[i].CompetitorID < result[j].CompetitorID
+	})
+
+	return result
+}
@@ -0,0 +1,149 @@
+package updater
+
+import (
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/lineage"
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/internal/store"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+)
+
+// Fetcher is the function used to get the finished Matches of a range from a
+// source.
+type Fetcher func(startDate, endDate time.Time) ([]*ranking.Match, error)
+
+// Updater is the struct that incrementally brings the Store up to date: it
+// resumes from the last stored period, fetches only what happened after it
+// and stores the periods that got closed since.
+type Updater struct {
+	Store          store.Store
+	Fetch          Fetcher
+	Resolver       *lineage.Resolver
+	StartDate      time.Time // Only used when the Store has no periods yet.
+	PeriodDuration int
+	SystemConstant float64        // See ranking.Ranker.
+	DefaultRating  *glicko.Rating // See ranking.Ranker.
+	Parallel       bool           // See ranking.Ranker.
+
+	// OnPeriod, when set, is called after each new RatingPeriod is stored.
+	OnPeriod func(ratingPeriod *glicko.RatingPeriod, teams map[int]*ranking.Team) error
+}
+
+// BuildUpdater builds an Updater.
+func BuildUpdater(
+	db store.Store, fetch Fetcher,
+	startDate time.Time, periodDuration int, resolver *lineage.Resolver,
+) *Updater {
+	return &Updater{
+		Store:          db,
+		Fetch:          fetch,
+		Resolver:       resolver,
+		StartDate:      startDate,
+		PeriodDuration: periodDuration,
+	}
+}
+
+// Update calculates and stores every period closed until the given moment.
+// Running it again for the same moment is a no-op: periods restart after the
+// stored checkpoint and Matches already rated (by id) are ignored.
+func (u *Updater) Update(until time.Time) ([]*glicko.RatingPeriod, error) {
+	lastPeriod, err := u.Store.LastPeriod()
+	if err != nil {
+		return nil, err
+	}
+
+	fetchStartDate := u.StartDate
+	if lastPeriod != nil {
+		fetchStartDate = lastPeriod.EndDate.Add(1 * time.Second)
+	}
+	if !fetchStartDate.Before(until) {
+		return []*glicko.RatingPeriod{}, nil
+	}
+
+	// Without a Fetcher only the Matches already stored are used.
+	if u.Fetch != nil {
+		fetched, err := u.Fetch(fetchStartDate, until)
+		if err != nil {
+			return nil, err
+		}
+		if err := u.Store.SaveMatches(fetched); err != nil {
+			return nil, err
+		}
+	}
+
+	// Matches can also get into the Store by other means (e.g. a previous
+	// run that failed before closing the period), so it is the source of
+	// truth from here on.
+	matches, err := u.pendingMatches(fetchStartDate, until)
+	if err != nil {
+		return nil, err
+	}
+
+	ranker, err := u.buildRanker(lastPeriod, until)
+	if err != nil {
+		return nil, err
+	}
+
+	return ranker.Rate(matches)
+}
+
+// pendingMatches returns the stored Matches of the range that weren't rated
+// yet.
+func (u *Updater) pendingMatches(startDate, endDate time.Time) ([]*ranking.Match, error) {
+	stored, err := u.Store.Matches(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	rated, err := u.Store.RatedMatchIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []*ranking.Match{}
+	for _, match := range stored {
+		if !rated[match.ID] {
+			matches = append(matches, match)
+		}
+	}
+	return matches, nil
+}
+
+// buildRanker builds a Ranker that continues from the checkpoint and stores
+// each period as soon as it is calculated.
+func (u *Updater) buildRanker(lastPeriod *glicko.RatingPeriod, until time.Time) (*ranking.Ranker, error) {
+	ranker := ranking.BuildRanker(u.StartDate, until, u.PeriodDuration, u.Resolver)
+	ranker.ClosedOnly = true
+	ranker.SystemConstant = u.SystemConstant
+	ranker.DefaultRating = u.DefaultRating
+	ranker.Parallel = u.Parallel
+
+	teams, err := u.Store.Teams()
+	if err != nil {
+		return nil, err
+	}
+	for id, team := range teams {
+		ranker.Teams[id] = team
+	}
+
+	if lastPeriod != nil {
+		ratings, err := u.Store.LatestRatings()
+		if err != nil {
+			return nil, err
+		}
+		ranker.Resume(lastPeriod, ratings)
+	}
+
+	ranker.OnPeriod = func(ratingPeriod *glicko.RatingPeriod) error {
+		if err := u.Store.SavePeriod(ratingPeriod); err != nil {
+			return err
+		}
+		if u.OnPeriod != nil {
+			return u.OnPeriod(ratingPeriod, ranker.Teams)
+		}
+		return nil
+	}
+
+	return ranker, nil
+}
@@ -0,0 +1,279 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/augustoccesar/go-ranking/internal/ranking"
+	"github.com/augustoccesar/go-ranking/internal/store"
+	"github.com/augustoccesar/go-ranking/pkg/glicko"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(day int) time.Time {
+	return time.Date(2019, 3, day, 12, 0, 0, 0, time.UTC)
+}
+
+// mockFetcher simulates a source that always returns every Match of the range,
+// counting how many times each one was returned.
+func mockFetcher(fetched map[int]int) Fetcher {
+	teamA := &ranking.Team{ID: 1, Name: "A"}
+	teamB := &ranking.Team{ID: 2, Name: "B"}
+	teamC := &ranking.Team{ID: 3, Name: "C"}
+
+	all := []*ranking.Match{
+		{ID: 1, Home: teamA, Away: teamB, Winner: teamA, StartTime: day(2)},
+		{ID: 2, Home: teamA, Away: teamC, Winner: teamC, StartTime: day(3)},
+		{ID: 3, Home: teamB, Away: teamC, Winner: teamB, StartTime: day(9)},
+		{ID: 4, Home: teamA, Away: teamB, Winner: teamB, StartTime: day(16)},
+	}
+
+	return func(startDate, endDate time.Time) ([]*ranking.Match, error) {
+		matches := []*ranking.Match{}
+		for _, match := range all {
+			if !match.StartTime.Before(startDate) && !match.StartTime.After(endDate) {
+				fetched[match.ID]++
+				matches = append(matches, match)
+			}
+		}
+		return matches, nil
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	db, _ := store.OpenSQLite(":memory:")
+	defer db.Close()
+
+	fetched := map[int]int{}
+	updater := BuildUpdater(db, mockFetcher(fetched), time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), 7, nil)
+
+	// Only the first period is closed by the 10th.
+	periods, err := updater.Update(day(10))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(periods))
+	assert.Equal(t, 1, periods[0].ID)
+	assert.Equal(t, 2, len(periods[0].Matches))
+
+	// Running again for the same moment doesn't calculate anything.
+	periods, err = updater.Update(day(10))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(periods))
+
+	// The next run resumes from the checkpoint and the ratings stored.
+	firstPeriodRatings, _ := db.LatestRatings()
+	periods, err = updater.Update(day(23))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(periods))
+	assert.Equal(t, 2, periods[0].ID)
+	assert.Equal(t, 1, len(periods[0].Matches))
+	assert.Equal(t, 3, periods[0].Matches[0].ID)
+	for _, competitor := range periods[0].Competitors {
+		assert.Equal(t, firstPeriodRatings[competitor.ID].Rating, competitor.PreRating.Rating)
+	}
+
+	stored, _ := db.Periods()
+	assert.Equal(t, 3, len(stored))
+
+	// Matches of closed periods are not fetched again, the ones of open
+	// periods are, since they can still change.
+	assert.Equal(t, 1, fetched[1])
+	assert.Equal(t, 3, fetched[3])
+}
+
+func TestUpdateIgnoresRatedMatches(t *testing.T) {
+	db, _ := store.OpenSQLite(":memory:")
+	defer db.Close()
+
+	fetched := map[int]int{}
+	updater := BuildUpdater(db, mockFetcher(fetched), time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), 7, nil)
+	updater.Update(day(10))
+
+	// Even if the source returns an old Match again it is not rated twice.
+	updater.Fetch = func(startDate, endDate time.Time) ([]*ranking.Match, error) {
+		return []*ranking.Match{
+			{ID: 1, Home: &ranking.Team{ID: 1}, Away: &ranking.Team{ID: 2}, StartTime: day(9)},
+		}, nil
+	}
+	periods, err := updater.Update(day(16))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(periods))
+	assert.Equal(t, 1, len(periods[0].Matches))
+	assert.Equal(t, 3, periods[0].Matches[0].ID)
+}
+
+func TestRecompute(t *testing.T) {
+	db, _ := store.OpenSQLite(":memory:")
+	defer db.Close()
+
+	updater := BuildUpdater(db, mockFetcher(map[int]int{}), time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), 7, nil)
+	updater.Update(day(23))
+	before, _ := db.Periods()
+
+	// Changing τ only affects the periods from the 10th on.
+	updater.SystemConstant = 1.2
+	recomputation, err := updater.Recompute(day(10), day(23), "τ 1.2")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, recomputation.PreviousVersion)
+	assert.Equal(t, 2, recomputation.Version.ID)
+	assert.Equal(t, 2, db.Version())
+	assert.Equal(t, 2, len(recomputation.Periods))
+	assert.Equal(t, 2, recomputation.Periods[0].ID)
+	assert.Equal(t, 1.2, recomputation.Periods[0].SystemConstant)
+	assert.Equal(t, 3, len(recomputation.Movements))
+
+	after, _ := db.Periods()
+	assert.Equal(t, 3, len(after))
+	assert.Equal(t, before[0].Competitors[0].PostRating.Rating, after[0].Competitors[0].PostRating.Rating)
+	assert.Equal(t, 0.5, after[0].SystemConstant)
+
+	// The previous Version is kept untouched.
+	versions, _ := db.Versions()
+	assert.Equal(t, 2, len(versions))
+	db.UseVersion(1)
+	previous, _ := db.Periods()
+	assert.Equal(t, 0.5, previous[2].SystemConstant)
+}
+
+// failingStore is a Store whose SavePeriod and DeleteVersion fail.
+type failingStore struct {
+	store.Store
+}
+
+var (
+	errSavePeriod    = errors.New("save period failed")
+	errDeleteVersion = errors.New("delete version failed")
+)
+
+func (s *failingStore) SavePeriod(ratingPeriod *glicko.RatingPeriod) error {
+	return errSavePeriod
+}
+
+func (s *failingStore) DeleteVersion(id int) error {
+	return errDeleteVersion
+}
+
+func TestRecomputeReportsFailedRollback(t *testing.T) {
+	db, _ := store.OpenSQLite(":memory:")
+	defer db.Close()
+
+	updater := BuildUpdater(db, mockFetcher(map[int]int{}), time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), 7, nil)
+	updater.Update(day(23))
+
+	updater.Store = &failingStore{Store: db}
+	_, err := updater.Recompute(day(10), day(23), "")
+
+	assert.True(t, errors.Is(err, errSavePeriod))
+	assert.True(t, errors.Is(err, errDeleteVersion))
+	// Going back to the previous Version still worked.
+	assert.Equal(t, 1, db.Version())
+}
+
+func TestRecomputeWithSameConfiguration(t *testing.T) {
+	db, _ := store.OpenSQLite(":memory:")
+	defer db.Close()
+
+	updater := BuildUpdater(db, mockFetcher(map[int]int{}), time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), 7, nil)
+	updater.Update(day(23))
+
+	recomputation, err := updater.Recompute(day(1), day(23), "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(recomputation.Periods))
+	for _, movement := range recomputation.Movements {
+		assert.Equal(t, 0.0, movement.Delta)
+	}
+}
+
+func TestDaemon(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranking.db")
+	startDate := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	fetched := map[int]int{}
+
+	db, _ := store.OpenSQLite(path)
+	daemon := BuildDaemon(BuildUpdater(db, mockFetcher(fetched), startDate, 7, nil), time.Hour)
+	daemon.Now = func() time.Time { return day(10) }
+
+	// The context is already done, so it stops after the first run.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	daemon.Run(ctx)
+
+	status := daemon.Status()
+	assert.True(t, status.Healthy)
+	assert.Equal(t, 1, status.Runs)
+	assert.Equal(t, 1, status.LastRun.Periods)
+	assert.Equal(t, day(10).Add(time.Hour), status.NextRun)
+	assert.Equal(t, 0, daemon.RunOnce().Periods)
+	db.Close()
+
+	// After a restart it resumes from the stored periods.
+	db, _ = store.OpenSQLite(path)
+	defer db.Close()
+	daemon = BuildDaemon(BuildUpdater(db, mockFetcher(fetched), startDate, 7, nil), time.Hour)
+	daemon.Now = func() time.Time { return day(23) }
+	ran := []int{}
+	daemon.OnRun = func(run *Run, periods []*glicko.RatingPeriod) {
+		for _, ratingPeriod := range periods {
+			ran = append(ran, ratingPeriod.ID)
+		}
+	}
+
+	assert.Equal(t, 2, daemon.RunOnce().Periods)
+	assert.Equal(t, []int{2, 3}, ran)
+	stored, _ := db.Periods()
+	assert.Equal(t, 3, len(stored))
+	assert.Equal(t, 1, fetched[1])
+
+	// A failed run turns the health check unhealthy until the next success.
+	daemon.Updater.Fetch = func(startDate, endDate time.Time) ([]*ranking.Match, error) {
+		return nil, fmt.Errorf("source unavailable")
+	}
+	daemon.Now = func() time.Time { return day(30) }
+	assert.Equal(t, "source unavailable", daemon.RunOnce().Error)
+
+	recorder := httptest.NewRecorder()
+	daemon.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"consecutive_failures":1`)
+}
+
+func TestAmend(t *testing.T) {
+	db, _ := store.OpenSQLite(":memory:")
+	defer db.Close()
+
+	// Matches entered by hand: there is no Fetcher, only the stored ones.
+	updater := BuildUpdater(db, nil, time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), 7, nil)
+	fetch := mockFetcher(map[int]int{})
+	matches, _ := fetch(day(1), day(23))
+	db.SaveMatches(matches)
+	updater.Update(day(16))
+
+	// A Match of the open period doesn't change the stored ones.
+	recomputation, err := updater.Amend(day(16), day(16), "late match")
+	assert.Nil(t, err)
+	assert.Nil(t, recomputation)
+
+	// Voiding a Match of a closed period recomputes from its period on.
+	assert.Nil(t, db.VoidMatch(3))
+	recomputation, err = updater.Amend(day(9), day(16), "void 3")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, recomputation.Version.ID)
+	assert.Equal(t, 1, len(recomputation.Periods))
+	assert.Equal(t, 2, recomputation.Periods[0].ID)
+	assert.Equal(t, 0, len(recomputation.Periods[0].Matches))
+
+	rated, _ := db.RatedMatchIDs()
+	assert.Equal(t, map[int]bool{1: true, 2: true}, rated)
+}
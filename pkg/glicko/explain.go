@@ -0,0 +1,62 @@
+package glicko
+
+import "math"
+
+// MatchExplanation is the struct that holds the terms of one Match on the
+// calculation of the new Rating of a Competitor.
+type MatchExplanation struct {
+	Match    *RankableMatch
+	Opponent *RankableCompetitor
+	G        float64 // doc-ref: g(φj)
+	E        float64 // doc-ref: E(µ, µj, φj)
+	Result   float64 // doc-ref: sj
+
+	// Parts of Δ and of µ' - µ that come from this Match: v·g(φj)·(sj - E)
+	// and φ'²·g(φj)·(sj - E). RatingContribution is the second one on the
+	// rating scale, so the ones of all Matches add up to r' - r.
+	DeltaContribution    float64
+	G2RatingContribution float64
+	RatingContribution   float64
+}
+
+// Explanation is the struct that holds the intermediate values of the
+// calculation of the new Rating of a Competitor on a RatingPeriod, in the
+// order of the steps of the specification.
+type Explanation struct {
+	CompetitorID        int
+	PreRating           *Rating
+	Matches             []*MatchExplanation
+	V                   float64 // doc-ref: v
+	Delta               float64 // doc-ref: Δ
+	A                   float64 // doc-ref: a = ln(σ²)
+	Volatility          float64 // doc-ref: σ'
+	PreRatingDerivation float64 // doc-ref: φ*
+	PostRating          *Rating
+}
+
+// explain records the values calculated by rate.
+func (ex *Explanation) explain(variables *variables, postRating *Rating, newPreRatingDerivation float64) {
+	ex.PreRating = variables.competitor.PreRating
+	ex.PostRating = postRating
+	ex.V = variables.v
+	ex.Delta = variables.delta
+	ex.A = variables.a
+	ex.Volatility = postRating.Volatility
+	ex.PreRatingDerivation = newPreRatingDerivation
+
+	g2RatingDerivationPow := math.Pow(postRating.G2RatingDerivation, 2)
+	for _, term := range variables.terms {
+		agg := term.g * (term.result - term.e)
+		match := &MatchExplanation{
+			Match:                term.match,
+			Opponent:             term.opponent,
+			G:                    term.g,
+			E:                    term.e,
+			Result:               term.result,
+			DeltaContribution:    variables.v * agg,
+			G2RatingContribution: g2RatingDerivationPow * agg,
+		}
+		match.RatingContribution = 173.7178 * match.G2RatingContribution
+		ex.Matches = append(ex.Matches, match)
+	}
+}
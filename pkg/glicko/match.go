@@ -1,7 +1,10 @@
 package glicko
 
 // RankableMatch is the struct that hold the information related to a Match.
+// The ID is optional and only used to identify the Match outside of the
+// calculation.
 type RankableMatch struct {
+	ID     int
 	Home   *RankableCompetitor
 	Away   *RankableCompetitor
 	Winner int
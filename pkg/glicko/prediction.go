@@ -0,0 +1,12 @@
+package glicko
+
+import "math"
+
+// ExpectedScore is the probability of the Competitor with the rating beating
+// the one with the opponent rating. Differently from the E used inside the
+// RatingPeriod, the uncertainty of both sides is considered, since none of
+// them is "fixed" when predicting a future Match.
+func ExpectedScore(rating *Rating, opponent *Rating) float64 {
+	combinedDerivation := math.Sqrt(math.Pow(rating.G2RatingDerivation, 2) + math.Pow(opponent.G2RatingDerivation, 2))
+	return e(rating.G2Rating, opponent.G2Rating, combinedDerivation)
+}
@@ -1,5 +1,14 @@
 package glicko
 
+import "math"
+
+// ConfidenceZ is the z-score of the 95% confidence interval of a Rating.
+const ConfidenceZ = 1.96
+
+// DefaultConservativeK is the amount of RDs subtracted from the rating on the
+// conservative rating when none is given.
+const DefaultConservativeK = 2.0
+
 // Rating is the struct that holds the Glicko2 data.
 type Rating struct {
 	Rating             float64 // doc-ref: r
@@ -28,3 +37,34 @@ func BuildRating(args ...float64) *Rating {
 func BuildDefaultRating() *Rating {
 	return BuildRating(1500, 350, 0.06)
 }
+
+// Inflate returns a new Rating with the uncertainty increased as it would be
+// after the given amount of rating periods without Matches. The rating and
+// volatility stay the same.
+func (r *Rating) Inflate(periods int) *Rating {
+	g2RatingDerivation := r.G2RatingDerivation
+	for i := 0; i < periods; i++ {
+		g2RatingDerivation = math.Sqrt(math.Pow(g2RatingDerivation, 2) + math.Pow(r.Volatility, 2)) // doc-ref: φ' = φ*
+	}
+
+	return &Rating{
+		Rating:             r.Rating,
+		RatingDerivation:   173.7178 * g2RatingDerivation,
+		Volatility:         r.Volatility,
+		G2Rating:           r.G2Rating,
+		G2RatingDerivation: g2RatingDerivation,
+	}
+}
+
+// ConfidenceInterval returns the 95% confidence interval of the rating
+// (r ± 1.96·RD).
+func (r *Rating) ConfidenceInterval() (low, high float64) {
+	return r.Rating - ConfidenceZ*r.RatingDerivation, r.Rating + ConfidenceZ*r.RatingDerivation
+}
+
+// Conservative returns the rating minus k RDs, a value the real strength is
+// likely above. Ranking by it keeps Teams with few Matches (high RD) from
+// passing the established ones.
+func (r *Rating) Conservative(k float64) float64 {
+	return r.Rating - k*r.RatingDerivation
+}
@@ -0,0 +1,39 @@
+package glicko
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInflate(t *testing.T) {
+	rating := BuildRating(1500, 200, 0.06)
+
+	assert.Equal(t, rating.RatingDerivation, rating.Inflate(0).RatingDerivation)
+
+	inflated := rating.Inflate(2)
+
+	expected := math.Sqrt(math.Pow(200/173.7178, 2) + 2*math.Pow(0.06, 2))
+	assert.LessOrEqual(t, math.Abs(expected-inflated.G2RatingDerivation), 0.000001)
+	assert.Equal(t, rating.Rating, inflated.Rating)
+	assert.Equal(t, 200.0, rating.RatingDerivation)
+}
+
+func TestConfidenceInterval(t *testing.T) {
+	rating := BuildRating(1500, 200, 0.06)
+
+	low, high := rating.ConfidenceInterval()
+	assert.LessOrEqual(t, math.Abs(1108-low), 0.000001)
+	assert.LessOrEqual(t, math.Abs(1892-high), 0.000001)
+}
+
+func TestConservative(t *testing.T) {
+	established := BuildRating(1650, 50, 0.06)
+	newcomer := BuildRating(1700, 290, 0.06)
+
+	assert.Equal(t, 1120.0, newcomer.Conservative(2))
+	assert.Equal(t, 1550.0, established.Conservative(DefaultConservativeK))
+	assert.Greater(t, established.Conservative(2), newcomer.Conservative(2))
+	assert.Equal(t, newcomer.Rating, newcomer.Conservative(0))
+}
@@ -4,12 +4,20 @@
 package glicko
 
 import (
+	"errors"
 	"math"
+	"runtime"
+	"sync"
 	"time"
 )
 
+// ErrCalculated is returned when adding a Match to a RatingPeriod that was
+// already calculated.
+var ErrCalculated = errors.New("glicko: rating period already calculated")
+
 // RatingPeriod holds information about the period to which the Glicko2
-// calculation will be based on.
+// calculation will be based on. The Competitors are registered by
+// AddBuiltMatch and AddNewMatch, and shouldn't be set otherwise.
 type RatingPeriod struct {
 	ID             int
 	StartDate      time.Time
@@ -17,6 +25,17 @@ type RatingPeriod struct {
 	SystemConstant float64
 	Matches        []*RankableMatch
 	Competitors    []*RankableCompetitor
+
+	// competitorIndex holds the Competitors by id and is only updated by
+	// addCompetitor, so the Competitors must only change through
+	// AddBuiltMatch and AddNewMatch. One appended directly is still found,
+	// by going over the Competitors, but a replaced one isn't noticed.
+	competitorIndex map[int]*RankableCompetitor
+
+	// mutex guards the Matches and Competitors while they are added, and
+	// calculated closes the RatingPeriod to new Matches.
+	mutex      sync.Mutex
+	calculated bool
 }
 
 // BuildRatingPeriod build a default RatingPeriod.
@@ -44,77 +63,285 @@ func BuildRatingPeriodWithTime(id int, startDate time.Time, endDate time.Time) *
 // While adding the Match to the RatingPeriod, already register the other
 // necessary data (link the Match also to the Competitors and "extract"
 // the Competitors to register on the RatingPeriod).
-func (rt *RatingPeriod) AddBuiltMatch(match *RankableMatch) {
+// It can be called from multiple goroutines, as long as the Competitors
+// aren't being added to another RatingPeriod at the same time. Once the
+// RatingPeriod is calculated it returns ErrCalculated.
+func (rt *RatingPeriod) AddBuiltMatch(match *RankableMatch) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	if rt.calculated {
+		return ErrCalculated
+	}
+
 	rt.Matches = append(rt.Matches, match)
 	rt.addNewCompetitors(match.Home, match.Away)
 	match.Home.AddMatch(match)
 	match.Away.AddMatch(match)
+	return nil
 }
 
 // AddNewMatch adds creates Matches and add them to the RatingPeriod.
 // While adding the Match to the RatingPeriod, already register the other
 // necessary data (link the Match also to the Competitors and "extract"
 // the Competitors to register on the RatingPeriod).
-func (rt *RatingPeriod) AddNewMatch(home *RankableCompetitor, away *RankableCompetitor, winner int) {
-	match := &RankableMatch{
-		Home:   home,
-		Away:   away,
-		Winner: winner,
-	}
+// See AddBuiltMatch about concurrency.
+func (rt *RatingPeriod) AddNewMatch(home *RankableCompetitor, away *RankableCompetitor, winner int) error {
+	return rt.AddBuiltMatch(BuildRankableMatch(home, away, winner))
+}
 
-	rt.Matches = append(rt.Matches, match)
-	rt.addNewCompetitors(home, away)
-	home.AddMatch(match)
-	away.AddMatch(match)
+// Calculated tells if the RatingPeriod was already calculated, and so no
+// longer takes Matches.
+func (rt *RatingPeriod) Calculated() bool {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	return rt.calculated
+}
+
+// MarkCalculated closes the RatingPeriod to new Matches without calculating
+// it, for one whose Competitors already have the PostRating of an earlier
+// Calculate, like a period loaded from a store.
+func (rt *RatingPeriod) MarkCalculated() {
+	rt.close()
 }
 
 // Calculate is responsible to glue all the magic together. At the end of it
 // all the Competitors have the `.PostRating` data, which contains the new
 // Glicko2 information after the results of the RatingPeriod.
+// It is Result applied to the Competitors, and closes the RatingPeriod to
+// new Matches.
 func (rt *RatingPeriod) Calculate() {
-	for _, competitor := range rt.Competitors {
-		newVolatility := newVolatility(competitor, rt.SystemConstant)
-		v := v(competitor)
+	rt.close()
+	rt.apply(rt.Result())
+}
 
-		newPreRatingDerivation := math.Sqrt(math.Pow(competitor.PreRating.G2RatingDerivation, 2) + math.Pow(newVolatility, 2)) // doc-ref: φ*
+// CalculateParallel does the same as Calculate, splitting the Competitors
+// between a pool of workers goroutines (GOMAXPROCS when workers isn't
+// positive). The new Rating of each Competitor only depends on the ratings
+// before the period, so the results are identical to the ones of Calculate.
+func (rt *RatingPeriod) CalculateParallel(workers int) {
+	rt.close()
+	rt.apply(rt.ResultParallel(workers))
+}
+
+// Result calculates the new Rating of every Competitor without changing
+// anything: neither the PostRating of the Competitors nor the RatingPeriod,
+// which keeps taking Matches. Calling it again gives the same PeriodResult.
+func (rt *RatingPeriod) Result() *PeriodResult {
+	return rt.ResultParallel(1)
+}
+
+// ResultParallel does the same as Result on a pool of workers goroutines,
+// like CalculateParallel.
+func (rt *RatingPeriod) ResultParallel(workers int) *PeriodResult {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(rt.Competitors) {
+		workers = len(rt.Competitors)
+	}
 
-		agg := 0.0
-		for _, match := range competitor.Matches {
-			opponent := match.OpponentOf(competitor)
+	// Each Competitor writes only on its own position, so the workers don't
+	// share anything they write.
+	ratings := make([]*Rating, len(rt.Competitors))
+	diagnostics := make([]Diagnostics, len(rt.Competitors))
+	calculate := func(i int) {
+		ratings[i], diagnostics[i] = rate(rt.Competitors[i], rt.SystemConstant, nil)
+	}
 
-			g := g(opponent.PreRating.G2RatingDerivation)
-			E := e(competitor.PreRating.G2Rating, opponent.PreRating.G2Rating, opponent.PreRating.G2RatingDerivation)
-			result := match.CompetitorResult(competitor)
+	if workers <= 1 {
+		for i := range rt.Competitors {
+			calculate(i)
+		}
+	} else {
+		positions := make(chan int, workers)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for position := range positions {
+					calculate(position)
+				}
+			}()
+		}
 
-			agg += g * (result - E)
+		for i := range rt.Competitors {
+			positions <- i
 		}
+		close(positions)
+		wg.Wait()
+	}
 
-		// Each attribute is set in one individual line instead of constructing
-		// the struct because each one depend on the result of the previous.
-		competitor.PostRating = &Rating{}
-		competitor.PostRating.G2RatingDerivation = 1 / (math.Sqrt((1 / math.Pow(newPreRatingDerivation, 2)) + (1 / v)))            // doc-ref: φ'
-		competitor.PostRating.G2Rating = competitor.PreRating.G2Rating + math.Pow(competitor.PostRating.G2RatingDerivation, 2)*agg // doc-ref: µ'
-		competitor.PostRating.Rating = 173.7178*competitor.PostRating.G2Rating + 1500                                              // doc-ref: r'
-		competitor.PostRating.RatingDerivation = 173.7178 * competitor.PostRating.G2RatingDerivation                               // doc-ref: RD'
-		competitor.PostRating.Volatility = newVolatility                                                                           // doc-ref: σ'
+	result := &PeriodResult{
+		PeriodID:    rt.ID,
+		Ratings:     make(map[int]Rating, len(rt.Competitors)),
+		Diagnostics: make(map[int]Diagnostics, len(rt.Competitors)),
+	}
+	for i, competitor := range rt.Competitors {
+		result.Ratings[competitor.ID] = *ratings[i]
+		result.Diagnostics[competitor.ID] = diagnostics[i]
+	}
+	return result
+}
+
+// apply sets the PostRating of the Competitors from the PeriodResult.
+func (rt *RatingPeriod) apply(result *PeriodResult) {
+	for _, competitor := range rt.Competitors {
+		competitor.PostRating = result.Rating(competitor.ID)
 	}
 }
 
+// Explain calculates the new Rating of a Competitor as Calculate does, but
+// keeping the intermediate values. The PostRating of the Competitor isn't
+// changed. Returns nil when the Competitor isn't on the RatingPeriod.
+func (rt *RatingPeriod) Explain(competitorID int) *Explanation {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	competitor := rt.lookup(competitorID)
+	if competitor == nil {
+		return nil
+	}
+
+	explanation := &Explanation{CompetitorID: competitorID}
+	rate(competitor, rt.SystemConstant, explanation)
+	return explanation
+}
+
+// Competitor returns the Competitor registered on the RatingPeriod with the
+// id, or nil when there is none.
+func (rt *RatingPeriod) Competitor(id int) *RankableCompetitor {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	return rt.lookup(id)
+}
+
+// close stops the RatingPeriod from taking new Matches. Any AddBuiltMatch in
+// progress finishes before it.
+func (rt *RatingPeriod) close() {
+	rt.mutex.Lock()
+	rt.calculated = true
+	rt.mutex.Unlock()
+}
+
+// rate calculates the new Rating of a Competitor. When explanation isn't nil,
+// the intermediate values are recorded on it.
+func rate(competitor *RankableCompetitor, constant float64, explanation *Explanation) (*Rating, Diagnostics) {
+	variables := buildVariables(competitor)
+	newVolatility := newVolatility(variables, constant)
+
+	newPreRatingDerivation := math.Sqrt(math.Pow(competitor.PreRating.G2RatingDerivation, 2) + math.Pow(newVolatility, 2)) // doc-ref: φ*
+
+	// Each attribute is set in one individual line instead of constructing
+	// the struct because each one depend on the result of the previous.
+	postRating := &Rating{}
+	postRating.G2RatingDerivation = 1 / (math.Sqrt((1 / math.Pow(newPreRatingDerivation, 2)) + (1 / variables.v))) // doc-ref: φ'
+	postRating.G2Rating = competitor.PreRating.G2Rating + math.Pow(postRating.G2RatingDerivation, 2)*variables.agg // doc-ref: µ'
+	postRating.Rating = 173.7178*postRating.G2Rating + 1500                                                        // doc-ref: r'
+	postRating.RatingDerivation = 173.7178 * postRating.G2RatingDerivation                                         // doc-ref: RD'
+	postRating.Volatility = newVolatility                                                                          // doc-ref: σ'
+
+	if explanation != nil {
+		explanation.explain(variables, postRating, newPreRatingDerivation)
+	}
+
+	return postRating, Diagnostics{
+		Matches:             len(variables.terms),
+		V:                   variables.v,
+		Delta:               variables.delta,
+		PreRatingDerivation: newPreRatingDerivation,
+	}
+}
+
+// term is the struct that holds the values of one Match of a Competitor that
+// only depend on the ratings before the period.
+type term struct {
+	match    *RankableMatch
+	opponent *RankableCompetitor
+	g        float64 // doc-ref: g(φj)
+	e        float64 // doc-ref: E(µ, µj, φj)
+	result   float64 // doc-ref: sj
+}
+
+// variables is the struct that holds the values of a Competitor that don't
+// change along the calculation. They are calculated once and reused by every
+// step, including each iteration of the volatility, instead of going over the
+// Matches again every time.
+type variables struct {
+	competitor *RankableCompetitor
+	terms      []term
+	v          float64
+	agg        float64 // doc-ref: sum of g(φj)(sj - E)
+	delta      float64
+	a          float64
+}
+
+// buildVariables calculates the variables of a Competitor.
+func buildVariables(competitor *RankableCompetitor) *variables {
+	variables := &variables{
+		competitor: competitor,
+		terms:      buildTerms(competitor),
+		a:          a(competitor),
+	}
+	variables.v = v(variables.terms)
+	for _, term := range variables.terms {
+		variables.agg += term.g * (term.result - term.e)
+	}
+	variables.delta = variables.v * variables.agg
+
+	return variables
+}
+
+// buildTerms calculates the term of each Match of the Competitor.
+func buildTerms(competitor *RankableCompetitor) []term {
+	terms := make([]term, len(competitor.Matches))
+	for i, match := range competitor.Matches {
+		opponent := match.OpponentOf(competitor)
+
+		terms[i] = term{
+			match:    match,
+			opponent: opponent,
+			g:        g(opponent.PreRating.G2RatingDerivation),
+			e:        e(competitor.PreRating.G2Rating, opponent.PreRating.G2Rating, opponent.PreRating.G2RatingDerivation),
+			result:   match.CompetitorResult(competitor),
+		}
+	}
+	return terms
+}
+
 // addCompetitor adds a Competitor to the list of Competitors inside the
 // RatingPeriod.
 func (rt *RatingPeriod) addCompetitor(competitor *RankableCompetitor) {
+	if rt.competitorIndex == nil {
+		rt.competitorIndex = map[int]*RankableCompetitor{}
+	}
 	rt.Competitors = append(rt.Competitors, competitor)
+	rt.competitorIndex[competitor.ID] = competitor
 }
 
 // containsCompetitor checks if the Competitor is already registered on the
 // list if Competitors of the RatingPeriod.
 func (rt *RatingPeriod) containsCompetitor(competitor *RankableCompetitor) bool {
-	for _, c := range rt.Competitors {
-		if c.ID == competitor.ID {
-			return true
+	return rt.lookup(competitor.ID) != nil
+}
+
+// lookup finds a Competitor by id on the competitorIndex, going over the
+// Competitors when it isn't there.
+func (rt *RatingPeriod) lookup(id int) *RankableCompetitor {
+	if competitor, ok := rt.competitorIndex[id]; ok {
+		return competitor
+	}
+
+	for _, competitor := range rt.Competitors {
+		if competitor.ID == id {
+			return competitor
 		}
 	}
-	return false
+	return nil
 }
 
 // addNewCompetitors adds a "set" Competitor to the list of Competitors
@@ -133,15 +360,10 @@ func (rt *RatingPeriod) addNewCompetitors(competitors ...*RankableCompetitor) {
 // formulas would be a repetition of the specification. If any questions
 // related to them occur, please check the glicko2.pdf located on this project.
 
-func v(competitor *RankableCompetitor) float64 {
+func v(terms []term) float64 {
 	agg := 0.0
-	for _, match := range competitor.Matches {
-		opponent := match.OpponentOf(competitor)
-
-		g := g(opponent.PreRating.G2RatingDerivation)
-		E := e(competitor.PreRating.G2Rating, opponent.PreRating.G2Rating, opponent.PreRating.G2RatingDerivation)
-
-		agg += math.Pow(g, 2) * E * (1 - E)
+	for _, term := range terms {
+		agg += math.Pow(term.g, 2) * term.e * (1 - term.e)
 	}
 
 	return math.Pow(agg, -1)
@@ -156,55 +378,39 @@ func e(baseCompetitorGlicko2Rating, opponentGlicko2Rating, opponentGlicko2Rating
 	return 1 / (1 + math.Exp(-g*(baseCompetitorGlicko2Rating-opponentGlicko2Rating)))
 }
 
-func delta(competitor *RankableCompetitor) float64 {
-	v := v(competitor)
-	agg := 0.0
-	for _, match := range competitor.Matches {
-		opponent := match.OpponentOf(competitor)
-
-		g := g(opponent.PreRating.G2RatingDerivation)
-		E := e(competitor.PreRating.G2Rating, opponent.PreRating.G2Rating, opponent.PreRating.G2RatingDerivation)
-		result := match.CompetitorResult(competitor)
-
-		agg += g * (result - E)
-	}
-
-	return v * agg
-}
-
 func a(competitor *RankableCompetitor) float64 {
 	return math.Log(math.Pow(competitor.PreRating.Volatility, 2))
 }
 
-func f(x float64, competitor *RankableCompetitor, constant float64) float64 {
-	deltaPow := math.Pow(delta(competitor), 2)
-	g2RatingDerivationPow := math.Pow(competitor.PreRating.G2RatingDerivation, 2)
+func f(x float64, variables *variables, constant float64) float64 {
+	deltaPow := math.Pow(variables.delta, 2)
+	g2RatingDerivationPow := math.Pow(variables.competitor.PreRating.G2RatingDerivation, 2)
 	ePow := math.Pow(math.E, x)
-	v := v(competitor)
+	v := variables.v
 
 	topLeft := ePow * (deltaPow - g2RatingDerivationPow - v - ePow)
 	bottomLeft := 2 * math.Pow(g2RatingDerivationPow+v+ePow, 2)
-	topRight := x - a(competitor)
+	topRight := x - variables.a
 	bottomRight := math.Pow(constant, 2)
 
 	return (topLeft / bottomLeft) - (topRight / bottomRight)
 }
 
 // constant: doc-ref: τ
-func newVolatility(competitor *RankableCompetitor, constant float64) float64 {
+func newVolatility(variables *variables, constant float64) float64 {
 	var B float64
-	A := a(competitor)
-	v := v(competitor)
-	delta := delta(competitor)
+	A := variables.a
+	v := variables.v
+	delta := variables.delta
 	e := 0.000001 // doc-ref: ε
 
-	if math.Pow(delta, 2) > math.Pow(competitor.PreRating.G2RatingDerivation, 2)+v {
-		B = math.Log(math.Pow(delta, 2) - math.Pow(competitor.PreRating.G2RatingDerivation, 2) - v)
+	if math.Pow(delta, 2) > math.Pow(variables.competitor.PreRating.G2RatingDerivation, 2)+v {
+		B = math.Log(math.Pow(delta, 2) - math.Pow(variables.competitor.PreRating.G2RatingDerivation, 2) - v)
 	} else {
 		k := 1.0
 		for {
 			x := A - (k * constant)
-			if f(x, competitor, constant) < 0 {
+			if f(x, variables, constant) < 0 {
 				k += 1.0
 			} else {
 				B = A - (k * constant)
@@ -213,13 +419,13 @@ func newVolatility(competitor *RankableCompetitor, constant float64) float64 {
 		}
 	}
 
-	fA := f(A, competitor, constant)
-	fB := f(B, competitor, constant)
+	fA := f(A, variables, constant)
+	fB := f(B, variables, constant)
 
 	for {
 		if math.Abs(B-A) > e {
 			C := A + ((A - B) * fA / (fB - fA))
-			fC := f(C, competitor, constant)
+			fC := f(C, variables, constant)
 
 			if fC*fB < 0 {
 				A = B
@@ -2,6 +2,9 @@ package glicko
 
 import (
 	"math"
+	"math/rand"
+	"sort"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,6 +37,83 @@ func TestAddNewMatch(t *testing.T) {
 	assert.Equal(t, 1, len(ratingPeriod.Matches))
 }
 
+func TestAddNewMatchConcurrently(t *testing.T) {
+	pool := make([]*RankableCompetitor, 20)
+	for i := range pool {
+		pool[i] = BuildRankableCompetitor(i+1, BuildDefaultRating())
+	}
+
+	ratingPeriod := BuildRatingPeriod(1)
+
+	// Each goroutine adds the Matches of one round robin round, so a
+	// Competitor is on Matches added by several goroutines.
+	var wg sync.WaitGroup
+	for round := 1; round < len(pool); round++ {
+		wg.Add(1)
+		go func(round int) {
+			defer wg.Done()
+			for i := 0; i < len(pool); i += 2 {
+				home, away := pool[i], pool[(i+round)%len(pool)]
+				assert.Nil(t, ratingPeriod.AddNewMatch(home, away, home.ID))
+				_ = ratingPeriod.Competitor(home.ID)
+			}
+		}(round)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 19*10, len(ratingPeriod.Matches))
+	assert.Equal(t, 20, len(ratingPeriod.Competitors))
+	matches := 0
+	for _, competitor := range ratingPeriod.Competitors {
+		matches += len(competitor.Matches)
+	}
+	assert.Equal(t, 2*len(ratingPeriod.Matches), matches)
+}
+
+func TestAddNewMatchAfterCalculate(t *testing.T) {
+	ratingPeriod := mockRatingPeriod(t)
+	assert.False(t, ratingPeriod.Calculated())
+
+	ratingPeriod.Calculate()
+	assert.True(t, ratingPeriod.Calculated())
+
+	competitor1 := ratingPeriod.Competitor(1)
+	competitor5 := BuildRankableCompetitor(5, BuildDefaultRating())
+	assert.Equal(t, ErrCalculated, ratingPeriod.AddNewMatch(competitor1, competitor5, 1))
+	assert.Equal(t, ErrCalculated, ratingPeriod.AddBuiltMatch(BuildRankableMatch(competitor1, competitor5, 5)))
+	assert.Equal(t, 3, len(ratingPeriod.Matches))
+	assert.Equal(t, 3, len(competitor1.Matches))
+	assert.Nil(t, ratingPeriod.Competitor(5))
+
+	parallel := randomRatingPeriod(10, 50)
+	parallel.CalculateParallel(4)
+	assert.Equal(t, ErrCalculated, parallel.AddNewMatch(parallel.Competitors[0], parallel.Competitors[1], -1))
+}
+
+func TestCompetitor(t *testing.T) {
+	ratingPeriod := mockRatingPeriod(t)
+
+	assert.Equal(t, 4, len(ratingPeriod.Competitors))
+	assert.Equal(t, ratingPeriod.Competitors[2], ratingPeriod.Competitor(3))
+	assert.Nil(t, ratingPeriod.Competitor(5))
+
+	// Competitors appended directly are still found.
+	competitor5 := BuildRankableCompetitor(5, BuildDefaultRating())
+	ratingPeriod.Competitors = append(ratingPeriod.Competitors, competitor5)
+	assert.Equal(t, competitor5, ratingPeriod.Competitor(5))
+
+	ratingPeriod.AddNewMatch(competitor5, ratingPeriod.Competitor(1), -1)
+	assert.Equal(t, 5, len(ratingPeriod.Competitors))
+
+	// The Competitors sorted in place.
+	sort.Slice(ratingPeriod.Competitors, func(i, j int) bool {
+		return ratingPeriod.Competitors[i].ID > ratingPeriod.Competitors[j].ID
+	})
+	for _, competitor := range ratingPeriod.Competitors {
+		assert.Equal(t, competitor, ratingPeriod.Competitor(competitor.ID))
+	}
+}
+
 func TestG(t *testing.T) {
 	ratingPeriod := mockRatingPeriod(t)
 	competitor1 := ratingPeriod.Competitors[0]
@@ -72,7 +152,7 @@ func TestV(t *testing.T) {
 	ratingPeriod := mockRatingPeriod(t)
 	competitor1 := ratingPeriod.Competitors[0]
 
-	result := v(competitor1)
+	result := v(buildTerms(competitor1))
 
 	assert.LessOrEqual(t, math.Abs(1.7785-result), 0.0005)
 }
@@ -81,7 +161,7 @@ func TestDelta(t *testing.T) {
 	ratingPeriod := mockRatingPeriod(t)
 	competitor1 := ratingPeriod.Competitors[0]
 
-	result := delta(competitor1)
+	result := buildVariables(competitor1).delta
 
 	assert.LessOrEqual(t, math.Abs(-0.4834-result), 0.001)
 }
@@ -90,7 +170,7 @@ func TestNewVolatile(t *testing.T) {
 	ratingPeriod := mockRatingPeriod(t)
 	competitor1 := ratingPeriod.Competitors[0]
 
-	result := newVolatility(competitor1, ratingPeriod.SystemConstant)
+	result := newVolatility(buildVariables(competitor1), ratingPeriod.SystemConstant)
 
 	assert.LessOrEqual(t, math.Abs(0.05999-result), 0.00001)
 }
@@ -105,3 +185,306 @@ func TestCalculate(t *testing.T) {
 	assert.LessOrEqual(t, math.Abs(151.52-competitor1.PostRating.RatingDerivation), 0.1)
 	assert.LessOrEqual(t, math.Abs(0.05999-competitor1.PostRating.Volatility), 0.00001)
 }
+
+func TestExplain(t *testing.T) {
+	ratingPeriod := mockRatingPeriod(t)
+
+	explanation := ratingPeriod.Explain(1)
+	assert.Nil(t, ratingPeriod.Competitors[0].PostRating)
+	assert.Nil(t, ratingPeriod.Explain(5))
+
+	ratingPeriod.Calculate()
+	competitor1 := ratingPeriod.Competitors[0]
+
+	assert.Equal(t, competitor1.PostRating, explanation.PostRating)
+	assert.Equal(t, buildVariables(competitor1).v, explanation.V)
+	assert.Equal(t, buildVariables(competitor1).delta, explanation.Delta)
+	assert.Equal(t, competitor1.PostRating.Volatility, explanation.Volatility)
+	assert.Len(t, explanation.Matches, 3)
+
+	deltaSum, ratingSum := 0.0, 0.0
+	for _, match := range explanation.Matches {
+		deltaSum += match.DeltaContribution
+		ratingSum += match.RatingContribution
+	}
+	assert.LessOrEqual(t, math.Abs(explanation.Delta-deltaSum), 1e-9)
+	assert.LessOrEqual(t, math.Abs(competitor1.PostRating.Rating-competitor1.PreRating.Rating-ratingSum), 1e-9)
+
+	assert.Equal(t, 2, explanation.Matches[0].Opponent.ID)
+	assert.LessOrEqual(t, math.Abs(0.9955-explanation.Matches[0].G), 0.0001)
+	assert.LessOrEqual(t, math.Abs(0.639-explanation.Matches[0].E), 0.001)
+	assert.Equal(t, 1.0, explanation.Matches[0].Result)
+}
+
+// randomRatingPeriod builds a RatingPeriod with the given amount of
+// Competitors and Matches between random pairs of them.
+func randomRatingPeriod(competitors int, matches int) *RatingPeriod {
+	random := rand.New(rand.NewSource(1))
+
+	pool := make([]*RankableCompetitor, competitors)
+	for i := range pool {
+		pool[i] = BuildRankableCompetitor(i+1, BuildRating(1200+random.Float64()*600, 30+random.Float64()*320, 0.06))
+	}
+
+	ratingPeriod := BuildRatingPeriod(1)
+	for i := 0; i < matches; i++ {
+		home := pool[random.Intn(competitors)]
+		away := pool[random.Intn(competitors)]
+		for away == home {
+			away = pool[random.Intn(competitors)]
+		}
+
+		winner := -1
+		switch random.Intn(5) {
+		case 0, 1:
+			winner = home.ID
+		case 2, 3:
+			winner = away.ID
+		}
+		ratingPeriod.AddNewMatch(home, away, winner)
+	}
+
+	return ratingPeriod
+}
+
+// naiveRate is the calculation as it was before the terms of the Matches
+// were precomputed, going over the Matches on every step and on every
+// iteration of the volatility. It is the reference of TestCalculateTerms and
+// the baseline of the benchmarks.
+func naiveRate(competitor *RankableCompetitor, constant float64) *Rating {
+	naiveV := func() float64 {
+		agg := 0.0
+		for _, match := range competitor.Matches {
+			opponent := match.OpponentOf(competitor)
+
+			g := g(opponent.PreRating.G2RatingDerivation)
+			E := e(competitor.PreRating.G2Rating, opponent.PreRating.G2Rating, opponent.PreRating.G2RatingDerivation)
+
+			agg += math.Pow(g, 2) * E * (1 - E)
+		}
+		return math.Pow(agg, -1)
+	}
+	naiveAgg := func() float64 {
+		agg := 0.0
+		for _, match := range competitor.Matches {
+			opponent := match.OpponentOf(competitor)
+
+			g := g(opponent.PreRating.G2RatingDerivation)
+			E := e(competitor.PreRating.G2Rating, opponent.PreRating.G2Rating, opponent.PreRating.G2RatingDerivation)
+			result := match.CompetitorResult(competitor)
+
+			agg += g * (result - E)
+		}
+		return agg
+	}
+	naiveDelta := func() float64 {
+		return naiveV() * naiveAgg()
+	}
+	naiveF := func(x float64) float64 {
+		deltaPow := math.Pow(naiveDelta(), 2)
+		g2RatingDerivationPow := math.Pow(competitor.PreRating.G2RatingDerivation, 2)
+		ePow := math.Pow(math.E, x)
+		v := naiveV()
+
+		topLeft := ePow * (deltaPow - g2RatingDerivationPow - v - ePow)
+		bottomLeft := 2 * math.Pow(g2RatingDerivationPow+v+ePow, 2)
+		topRight := x - a(competitor)
+		bottomRight := math.Pow(constant, 2)
+
+		return (topLeft / bottomLeft) - (topRight / bottomRight)
+	}
+
+	var B float64
+	A := a(competitor)
+	v := naiveV()
+	delta := naiveDelta()
+	if math.Pow(delta, 2) > math.Pow(competitor.PreRating.G2RatingDerivation, 2)+v {
+		B = math.Log(math.Pow(delta, 2) - math.Pow(competitor.PreRating.G2RatingDerivation, 2) - v)
+	} else {
+		k := 1.0
+		for naiveF(A-(k*constant)) < 0 {
+			k += 1.0
+		}
+		B = A - (k * constant)
+	}
+	fA, fB := naiveF(A), naiveF(B)
+	for math.Abs(B-A) > 0.000001 {
+		C := A + ((A - B) * fA / (fB - fA))
+		fC := naiveF(C)
+		if fC*fB < 0 {
+			A, fA = B, fB
+		} else {
+			fA = fA / 2
+		}
+		B, fB = C, fC
+	}
+	newVolatility := math.Pow(math.E, (A / 2))
+
+	newPreRatingDerivation := math.Sqrt(math.Pow(competitor.PreRating.G2RatingDerivation, 2) + math.Pow(newVolatility, 2))
+
+	postRating := &Rating{}
+	postRating.G2RatingDerivation = 1 / (math.Sqrt((1 / math.Pow(newPreRatingDerivation, 2)) + (1 / naiveV())))
+	postRating.G2Rating = competitor.PreRating.G2Rating + math.Pow(postRating.G2RatingDerivation, 2)*naiveAgg()
+	postRating.Rating = 173.7178*postRating.G2Rating + 1500
+	postRating.RatingDerivation = 173.7178 * postRating.G2RatingDerivation
+	postRating.Volatility = newVolatility
+	return postRating
+}
+
+func TestCalculateTerms(t *testing.T) {
+	ratingPeriod := randomRatingPeriod(50, 2000)
+	ratingPeriod.Calculate()
+
+	for _, competitor := range ratingPeriod.Competitors {
+		assert.Equal(t, naiveRate(competitor, ratingPeriod.SystemConstant), competitor.PostRating)
+	}
+}
+
+func TestResult(t *testing.T) {
+	ratingPeriod := mockRatingPeriod(t)
+
+	result := ratingPeriod.Result()
+	for _, competitor := range ratingPeriod.Competitors {
+		assert.Nil(t, competitor.PostRating)
+	}
+	assert.False(t, ratingPeriod.Calculated())
+	assert.Equal(t, 1, result.PeriodID)
+	assert.Len(t, result.Ratings, 4)
+	assert.Nil(t, result.Rating(5))
+
+	rating1 := result.Ratings[1]
+	assert.LessOrEqual(t, math.Abs(1464.06-rating1.Rating), 0.1)
+	assert.LessOrEqual(t, math.Abs(151.52-rating1.RatingDerivation), 0.1)
+
+	diagnostics1 := result.Diagnostics[1]
+	assert.Equal(t, 3, diagnostics1.Matches)
+	assert.LessOrEqual(t, math.Abs(1.7785-diagnostics1.V), 0.0005)
+	assert.LessOrEqual(t, math.Abs(-0.4834-diagnostics1.Delta), 0.001)
+
+	// Changing a Rating taken from the result doesn't change it.
+	result.Rating(1).Rating = 0
+	assert.Equal(t, rating1, result.Ratings[1])
+
+	// Same result when called again, in parallel and after Calculate, which
+	// applies it to the Competitors.
+	assert.Equal(t, result, ratingPeriod.Result())
+	assert.Equal(t, result, ratingPeriod.ResultParallel(4))
+	ratingPeriod.Calculate()
+	assert.Equal(t, result, ratingPeriod.Result())
+	for _, competitor := range ratingPeriod.Competitors {
+		assert.Equal(t, result.Rating(competitor.ID), competitor.PostRating)
+	}
+}
+
+func TestCalculateParallel(t *testing.T) {
+	for _, workers := range []int{0, 1, 4, 100} {
+		serial := randomRatingPeriod(200, 3000)
+		serial.Calculate()
+
+		parallel := randomRatingPeriod(200, 3000)
+		parallel.CalculateParallel(workers)
+
+		for i, competitor := range parallel.Competitors {
+			assert.Equal(t, serial.Competitors[i].ID, competitor.ID)
+			assert.Equal(t, serial.Competitors[i].PostRating, competitor.PostRating)
+		}
+	}
+}
+
+func benchmarkCalculate(b *testing.B, competitors int, matches int) {
+	ratingPeriod := randomRatingPeriod(competitors, matches)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ratingPeriod.Calculate()
+	}
+}
+
+func benchmarkCalculateParallel(b *testing.B, competitors int, matches int) {
+	ratingPeriod := randomRatingPeriod(competitors, matches)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ratingPeriod.CalculateParallel(0)
+	}
+}
+
+func benchmarkNaiveCalculate(b *testing.B, competitors int, matches int) {
+	ratingPeriod := randomRatingPeriod(competitors, matches)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, competitor := range ratingPeriod.Competitors {
+			competitor.PostRating = naiveRate(competitor, ratingPeriod.SystemConstant)
+		}
+	}
+}
+
+func BenchmarkCalculate(b *testing.B)              { benchmarkCalculate(b, 100, 1000) }
+func BenchmarkCalculateLarge(b *testing.B)         { benchmarkCalculate(b, 1000, 20000) }
+func BenchmarkCalculateParallel(b *testing.B)      { benchmarkCalculateParallel(b, 100, 1000) }
+func BenchmarkCalculateParallelLarge(b *testing.B) { benchmarkCalculateParallel(b, 1000, 20000) }
+func BenchmarkNaiveCalculate(b *testing.B)         { benchmarkNaiveCalculate(b, 100, 1000) }
+func BenchmarkNaiveCalculateLarge(b *testing.B)    { benchmarkNaiveCalculate(b, 1000, 20000) }
+
+// benchmarkPairs builds the positions of the home and away Competitors of the
+// Matches of the construction benchmarks.
+func benchmarkPairs(competitors int, matches int) [][2]int {
+	random := rand.New(rand.NewSource(1))
+
+	pairs := make([][2]int, matches)
+	for i := range pairs {
+		home := random.Intn(competitors)
+		pairs[i] = [2]int{home, (home + 1 + random.Intn(competitors-1)) % competitors}
+	}
+	return pairs
+}
+
+// benchmarkPool builds the Competitors of the construction benchmarks.
+func benchmarkPool(competitors int) []*RankableCompetitor {
+	pool := make([]*RankableCompetitor, competitors)
+	for i := range pool {
+		pool[i] = BuildRankableCompetitor(i+1, BuildDefaultRating())
+	}
+	return pool
+}
+
+func BenchmarkAddNewMatch(b *testing.B) {
+	pairs := benchmarkPairs(10000, 100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pool := benchmarkPool(10000)
+		ratingPeriod := BuildRatingPeriod(1)
+		for _, pair := range pairs {
+			ratingPeriod.AddNewMatch(pool[pair[0]], pool[pair[1]], pool[pair[0]].ID)
+		}
+	}
+}
+
+// BenchmarkLinearAddNewMatch is the baseline of BenchmarkAddNewMatch, looking
+// for the Competitors with a scan of the slice as before the index.
+func BenchmarkLinearAddNewMatch(b *testing.B) {
+	pairs := benchmarkPairs(10000, 100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pool := benchmarkPool(10000)
+		ratingPeriod := BuildRatingPeriod(1)
+		for _, pair := range pairs {
+			home, away := pool[pair[0]], pool[pair[1]]
+			match := BuildRankableMatch(home, away, home.ID)
+
+			ratingPeriod.Matches = append(ratingPeriod.Matches, match)
+			for _, competitor := range []*RankableCompetitor{home, away} {
+				found := false
+				for _, c := range ratingPeriod.Competitors {
+					if c.ID == competitor.ID {
+						found = true
+						break
+					}
+				}
+				if !found {
+					ratingPeriod.Competitors = append(ratingPeriod.Competitors, competitor)
+				}
+			}
+			home.AddMatch(match)
+			away.AddMatch(match)
+		}
+	}
+}
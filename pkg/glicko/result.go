@@ -0,0 +1,29 @@
+package glicko
+
+// Diagnostics is the struct that holds the main intermediate values of the
+// calculation of a Competitor, without the detail by Match of Explain.
+type Diagnostics struct {
+	Matches             int
+	V                   float64 // doc-ref: v
+	Delta               float64 // doc-ref: Δ
+	PreRatingDerivation float64 // doc-ref: φ*
+}
+
+// PeriodResult is the struct that holds the outcome of a RatingPeriod: the
+// new Rating and the Diagnostics of each Competitor, by id. They are values,
+// so changing them doesn't affect the Competitors nor other results.
+type PeriodResult struct {
+	PeriodID    int
+	Ratings     map[int]Rating
+	Diagnostics map[int]Diagnostics
+}
+
+// Rating returns the new Rating of a Competitor, or nil when it isn't on the
+// RatingPeriod. It is a copy, changing it doesn't change the PeriodResult.
+func (pr *PeriodResult) Rating(competitorID int) *Rating {
+	rating, ok := pr.Ratings[competitorID]
+	if !ok {
+		return nil
+	}
+	return &rating
+}
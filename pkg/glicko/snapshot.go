@@ -0,0 +1,279 @@
+package glicko
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format written by this
+// package. It must be bumped on any incompatible change of the structs bellow.
+const SnapshotVersion = 1
+
+// Ledger is the struct that groups a list of RatingPeriods with the latest
+// Rating of every Competitor.
+type Ledger struct {
+	Periods []*RatingPeriod
+	Ratings map[int]*Rating
+}
+
+// Snapshot is the serializable form of a Ledger (or of a single
+// RatingPeriod). Since Competitors and Matches point to each other they can't
+// be marshalled directly, so the Matches reference the Competitors by id and
+// the links are rebuilt on Restore.
+type Snapshot struct {
+	Version int                     `json:"version"`
+	Periods []*PeriodSnapshot       `json:"periods"`
+	Ratings map[int]*RatingSnapshot `json:"ratings,omitempty"`
+}
+
+// PeriodSnapshot is the serializable form of a RatingPeriod.
+type PeriodSnapshot struct {
+	ID             int                   `json:"id"`
+	StartDate      time.Time             `json:"start_date"`
+	EndDate        time.Time             `json:"end_date"`
+	SystemConstant float64               `json:"system_constant"`
+	Competitors    []*CompetitorSnapshot `json:"competitors"`
+	Matches        []*MatchSnapshot      `json:"matches"`
+}
+
+// CompetitorSnapshot is the serializable form of a RankableCompetitor.
+type CompetitorSnapshot struct {
+	ID         int             `json:"id"`
+	PreRating  *RatingSnapshot `json:"pre_rating"`
+	PostRating *RatingSnapshot `json:"post_rating,omitempty"`
+}
+
+// MatchSnapshot is the serializable form of a RankableMatch.
+type MatchSnapshot struct {
+	ID     int `json:"id,omitempty"`
+	Home   int `json:"home"`
+	Away   int `json:"away"`
+	Winner int `json:"winner"`
+}
+
+// RatingSnapshot is the serializable form of a Rating. The Glicko2 scale
+// values are kept too, so a restored Rating is exactly the same.
+type RatingSnapshot struct {
+	Rating             float64 `json:"rating"`
+	RatingDerivation   float64 `json:"rd"`
+	Volatility         float64 `json:"volatility"`
+	G2Rating           float64 `json:"g2_rating"`
+	G2RatingDerivation float64 `json:"g2_rd"`
+}
+
+// SnapshotPeriod builds the Snapshot of a single RatingPeriod.
+func SnapshotPeriod(ratingPeriod *RatingPeriod) *Snapshot {
+	return &Snapshot{
+		Version: SnapshotVersion,
+		Periods: []*PeriodSnapshot{snapshotPeriod(ratingPeriod)},
+	}
+}
+
+// SnapshotLedger builds the Snapshot of a Ledger.
+func SnapshotLedger(ledger *Ledger) *Snapshot {
+	snapshot := &Snapshot{
+		Version: SnapshotVersion,
+		Periods: []*PeriodSnapshot{},
+		Ratings: map[int]*RatingSnapshot{},
+	}
+
+	for _, ratingPeriod := range ledger.Periods {
+		snapshot.Periods = append(snapshot.Periods, snapshotPeriod(ratingPeriod))
+	}
+	for id, rating := range ledger.Ratings {
+		snapshot.Ratings[id] = snapshotRating(rating)
+	}
+
+	return snapshot
+}
+
+// Restore rebuilds the Ledger of the Snapshot, with the Matches linked to the
+// Competitors of their RatingPeriod.
+func (s *Snapshot) Restore() (*Ledger, error) {
+	if s.Version < 1 || s.Version > SnapshotVersion {
+		return nil, fmt.Errorf("glicko: unsupported snapshot version %d", s.Version)
+	}
+
+	ledger := &Ledger{
+		Periods: []*RatingPeriod{},
+		Ratings: map[int]*Rating{},
+	}
+
+	for _, periodSnapshot := range s.Periods {
+		ratingPeriod, err := periodSnapshot.restore()
+		if err != nil {
+			return nil, err
+		}
+		ledger.Periods = append(ledger.Periods, ratingPeriod)
+	}
+	for id, ratingSnapshot := range s.Ratings {
+		rating, err := ratingSnapshot.restore()
+		if err != nil {
+			return nil, fmt.Errorf("glicko: rating of competitor %d: %v", id, err)
+		}
+		ledger.Ratings[id] = rating
+	}
+
+	return ledger, nil
+}
+
+// WriteJSON writes the Snapshot as indented JSON.
+func (s *Snapshot) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// WriteGob writes the Snapshot in the compact gob format.
+func (s *Snapshot) WriteGob(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(s)
+}
+
+// ReadJSONSnapshot reads a Snapshot written by WriteJSON.
+func ReadJSONSnapshot(r io.Reader) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if err := json.NewDecoder(r).Decode(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// ReadGobSnapshot reads a Snapshot written by WriteGob.
+func ReadGobSnapshot(r io.Reader) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if err := gob.NewDecoder(r).Decode(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func snapshotPeriod(ratingPeriod *RatingPeriod) *PeriodSnapshot {
+	periodSnapshot := &PeriodSnapshot{
+		ID:             ratingPeriod.ID,
+		StartDate:      ratingPeriod.StartDate,
+		EndDate:        ratingPeriod.EndDate,
+		SystemConstant: ratingPeriod.SystemConstant,
+		Competitors:    []*CompetitorSnapshot{},
+		Matches:        []*MatchSnapshot{},
+	}
+
+	for _, competitor := range ratingPeriod.Competitors {
+		periodSnapshot.Competitors = append(periodSnapshot.Competitors, &CompetitorSnapshot{
+			ID:         competitor.ID,
+			PreRating:  snapshotRating(competitor.PreRating),
+			PostRating: snapshotRating(competitor.PostRating),
+		})
+	}
+	for _, match := range ratingPeriod.Matches {
+		periodSnapshot.Matches = append(periodSnapshot.Matches, &MatchSnapshot{
+			ID:     match.ID,
+			Home:   match.Home.ID,
+			Away:   match.Away.ID,
+			Winner: match.Winner,
+		})
+	}
+
+	return periodSnapshot
+}
+
+func (ps *PeriodSnapshot) restore() (*RatingPeriod, error) {
+	ratingPeriod := BuildRatingPeriodWithTime(ps.ID, ps.StartDate, ps.EndDate)
+	if ps.SystemConstant != 0 {
+		// A missing one keeps the default τ, as zero would break the
+		// volatility.
+		ratingPeriod.SystemConstant = ps.SystemConstant
+	}
+
+	calculated := len(ps.Competitors) > 0
+	competitors := map[int]*RankableCompetitor{}
+	for _, competitorSnapshot := range ps.Competitors {
+		if competitorSnapshot.PreRating == nil {
+			return nil, fmt.Errorf("glicko: competitor %d of period %d without pre rating", competitorSnapshot.ID, ps.ID)
+		}
+
+		preRating, err := competitorSnapshot.PreRating.restore()
+		if err != nil {
+			return nil, fmt.Errorf("glicko: pre rating of competitor %d of period %d: %v", competitorSnapshot.ID, ps.ID, err)
+		}
+		postRating, err := competitorSnapshot.PostRating.restore()
+		if err != nil {
+			return nil, fmt.Errorf("glicko: post rating of competitor %d of period %d: %v", competitorSnapshot.ID, ps.ID, err)
+		}
+		calculated = calculated && postRating != nil
+
+		competitor := BuildRankableCompetitor(competitorSnapshot.ID, preRating)
+		competitor.PostRating = postRating
+		competitors[competitor.ID] = competitor
+		ratingPeriod.addCompetitor(competitor)
+	}
+
+	for _, matchSnapshot := range ps.Matches {
+		home, homeOk := competitors[matchSnapshot.Home]
+		away, awayOk := competitors[matchSnapshot.Away]
+		if !homeOk || !awayOk {
+			return nil, fmt.Errorf("glicko: match %d-%d of period %d references unknown competitors",
+				matchSnapshot.Home, matchSnapshot.Away, ps.ID)
+		}
+
+		match := BuildRankableMatch(home, away, matchSnapshot.Winner)
+		match.ID = matchSnapshot.ID
+		if err := ratingPeriod.AddBuiltMatch(match); err != nil {
+			return nil, err
+		}
+	}
+
+	// A period saved after Calculate is restored as calculated, so it takes
+	// no new Matches.
+	if calculated {
+		ratingPeriod.close()
+	}
+
+	return ratingPeriod, nil
+}
+
+func snapshotRating(rating *Rating) *RatingSnapshot {
+	if rating == nil {
+		return nil
+	}
+	return &RatingSnapshot{
+		Rating:             rating.Rating,
+		RatingDerivation:   rating.RatingDerivation,
+		Volatility:         rating.Volatility,
+		G2Rating:           rating.G2Rating,
+		G2RatingDerivation: rating.G2RatingDerivation,
+	}
+}
+
+// restore rebuilds the Rating. The Glicko2 scale values missing on the
+// snapshot (hand written or from an older writer) are calculated from the
+// rating and RD, as BuildRating does, and ones that don't match them are
+// refused.
+func (rs *RatingSnapshot) restore() (*Rating, error) {
+	if rs == nil {
+		return nil, nil
+	}
+
+	rating := BuildRating(rs.Rating, rs.RatingDerivation, rs.Volatility)
+	if rs.G2RatingDerivation == 0 && rs.RatingDerivation != 0 {
+		return rating, nil
+	}
+
+	if !closeTo(rs.G2Rating, rating.G2Rating) || !closeTo(rs.G2RatingDerivation, rating.G2RatingDerivation) {
+		return nil, fmt.Errorf("glicko scale values (%g, %g) don't match rating %g and RD %g",
+			rs.G2Rating, rs.G2RatingDerivation, rs.Rating, rs.RatingDerivation)
+	}
+
+	// The stored values are kept, so a restored Rating is exactly the same.
+	rating.G2Rating = rs.G2Rating
+	rating.G2RatingDerivation = rs.G2RatingDerivation
+	return rating, nil
+}
+
+// closeTo tells if two values are the same but for rounding errors.
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
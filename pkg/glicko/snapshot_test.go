@@ -0,0 +1,155 @@
+package glicko
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertSamePeriod(t *testing.T, expected *RatingPeriod, actual *RatingPeriod) {
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.True(t, expected.StartDate.Equal(actual.StartDate))
+	assert.True(t, expected.EndDate.Equal(actual.EndDate))
+	assert.Equal(t, expected.SystemConstant, actual.SystemConstant)
+	assert.Equal(t, len(expected.Competitors), len(actual.Competitors))
+	assert.Equal(t, len(expected.Matches), len(actual.Matches))
+
+	for i, competitor := range actual.Competitors {
+		assert.Equal(t, expected.Competitors[i].ID, competitor.ID)
+		assert.Equal(t, *expected.Competitors[i].PreRating, *competitor.PreRating)
+		assert.Equal(t, *expected.Competitors[i].PostRating, *competitor.PostRating)
+		assert.Equal(t, len(expected.Competitors[i].Matches), len(competitor.Matches))
+	}
+
+	// The pointer graph is rebuilt: Matches point to the Competitors of the
+	// period and the Competitors to their Matches.
+	for _, match := range actual.Matches {
+		assert.True(t, actual.Competitor(match.Home.ID) == match.Home)
+		assert.True(t, actual.Competitor(match.Away.ID) == match.Away)
+		assert.Contains(t, match.Home.Matches, match)
+		assert.Contains(t, match.Away.Matches, match)
+	}
+}
+
+func TestSnapshotJSON(t *testing.T) {
+	ratingPeriod := mockRatingPeriod(t)
+	ratingPeriod.Calculate()
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, SnapshotPeriod(ratingPeriod).WriteJSON(buffer))
+
+	snapshot, err := ReadJSONSnapshot(buffer)
+	assert.Nil(t, err)
+	ledger, err := snapshot.Restore()
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(ledger.Periods))
+	assertSamePeriod(t, ratingPeriod, ledger.Periods[0])
+}
+
+func TestSnapshotGob(t *testing.T) {
+	ratingPeriod := mockRatingPeriod(t)
+	ratingPeriod.Calculate()
+	ratings := map[int]*Rating{}
+	for _, competitor := range ratingPeriod.Competitors {
+		ratings[competitor.ID] = competitor.PostRating
+	}
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, SnapshotLedger(&Ledger{Periods: []*RatingPeriod{ratingPeriod}, Ratings: ratings}).WriteGob(buffer))
+
+	snapshot, err := ReadGobSnapshot(buffer)
+	assert.Nil(t, err)
+	ledger, err := snapshot.Restore()
+	assert.Nil(t, err)
+
+	assertSamePeriod(t, ratingPeriod, ledger.Periods[0])
+	assert.Equal(t, len(ratings), len(ledger.Ratings))
+	for id, rating := range ratings {
+		assert.Equal(t, *rating, *ledger.Ratings[id])
+	}
+}
+
+func TestSnapshotFixture(t *testing.T) {
+	file, err := os.Open("testdata/period_v1.json")
+	assert.Nil(t, err)
+	defer file.Close()
+
+	snapshot, err := ReadJSONSnapshot(file)
+	assert.Nil(t, err)
+	ledger, err := snapshot.Restore()
+	assert.Nil(t, err)
+
+	competitor1 := ledger.Periods[0].Competitors[0]
+	assert.LessOrEqual(t, math.Abs(1464.06-competitor1.PostRating.Rating), 0.1)
+	assert.LessOrEqual(t, math.Abs(151.52-competitor1.PostRating.RatingDerivation), 0.1)
+
+	// It is restored as calculated, so it takes no new Matches.
+	assert.True(t, ledger.Periods[0].Calculated())
+	assert.Equal(t, ErrCalculated, ledger.Periods[0].AddNewMatch(competitor1, BuildRankableCompetitor(9, BuildDefaultRating()), 9))
+
+	// Calculating the restored period again gives the same result.
+	expected := *competitor1.PostRating
+	ledger.Periods[0].Calculate()
+	assert.Equal(t, expected, *competitor1.PostRating)
+}
+
+func TestSnapshotUnsupportedVersion(t *testing.T) {
+	snapshot, _ := ReadJSONSnapshot(strings.NewReader(`{"version": 99, "periods": []}`))
+
+	_, err := snapshot.Restore()
+
+	assert.NotNil(t, err)
+}
+
+func TestSnapshotUnknownCompetitor(t *testing.T) {
+	snapshot, _ := ReadJSONSnapshot(strings.NewReader(`{"version": 1, "periods": [{
+		"id": 1,
+		"competitors": [{"id": 1, "pre_rating": {"rating": 1500, "rd": 350, "volatility": 0.06}}],
+		"matches": [{"home": 1, "away": 2, "winner": 1}]
+	}]}`))
+
+	_, err := snapshot.Restore()
+
+	assert.NotNil(t, err)
+}
+
+func TestSnapshotWithoutGlicko2Scale(t *testing.T) {
+	snapshot, _ := ReadJSONSnapshot(strings.NewReader(`{"version": 1, "periods": [{
+		"id": 1,
+		"competitors": [
+			{"id": 1, "pre_rating": {"rating": 1500, "rd": 200, "volatility": 0.06}},
+			{"id": 2, "pre_rating": {"rating": 1400, "rd": 30, "volatility": 0.06}}
+		],
+		"matches": [{"home": 1, "away": 2, "winner": 1}]
+	}]}`))
+
+	ledger, err := snapshot.Restore()
+	assert.Nil(t, err)
+
+	restored := ledger.Periods[0]
+	assert.Equal(t, *BuildRating(1400, 30, 0.06), *restored.Competitor(2).PreRating)
+	assert.False(t, restored.Calculated())
+
+	expected := BuildRatingPeriod(1)
+	expected.AddNewMatch(BuildRankableCompetitor(1, BuildRating(1500, 200, 0.06)), BuildRankableCompetitor(2, BuildRating(1400, 30, 0.06)), 1)
+	expected.Calculate()
+	restored.Calculate()
+	assert.Equal(t, *expected.Competitor(1).PostRating, *restored.Competitor(1).PostRating)
+}
+
+func TestSnapshotInconsistentGlicko2Scale(t *testing.T) {
+	snapshot, _ := ReadJSONSnapshot(strings.NewReader(`{"version": 1, "periods": [{
+		"id": 1,
+		"competitors": [{"id": 1, "pre_rating": {"rating": 1700, "rd": 200, "volatility": 0.06, "g2_rating": 0, "g2_rd": 1.1512924985234674}}],
+		"matches": []
+	}]}`))
+
+	_, err := snapshot.Restore()
+
+	assert.NotNil(t, err)
+}